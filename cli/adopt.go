@@ -0,0 +1,218 @@
+/*
+File: adopt.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 19:41:05
+
+Description: 子命令 'adopt' 的实现
+*/
+
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/gookit/color"
+	"github.com/yhyj/curator/general"
+)
+
+// adoptCandidate 可以纳入管理的本地存储库
+type adoptCandidate struct {
+	path     string // 本地存储库路径
+	url      string // 存储库源地址
+	username string // 存储库源用户名
+	repoName string // 存储库名
+}
+
+// AdoptRepos 扫描存储目录，将不在配置文件中的本地存储库纳入管理
+//
+//   - 存储库源、用户名和存储库名从 origin 地址推断，与已有存储库源都不匹配时新增存储库源
+//   - 已有来自其他存储库源或用户的同名存储库时报告该所有者冲突，并以 '<用户名>/<存储库名>' 作为存储库键纳入管理
+//   - 纳入管理的存储库会按 curator 的约定修改 origin（SSH 地址）并为有镜像源的存储库添加 pushurl
+//   - 以文本方式修改配置文件，保留原有格式和注释
+//
+// 参数：
+//   - configFile: 配置文件路径
+func AdoptRepos(configFile string) {
+	config, err := loadConfig(configFile)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 扫描存储目录，跳过已配置的存储库和缓存目录
+	skipPaths := []string{config.Cache.Path}
+	for _, repoName := range config.Git.Repos {
+		skipPaths = append(skipPaths, general.GetRepoPath(config, repoName))
+	}
+	repoPaths, err := general.FindLocalRepos(config.Storage.Path, skipPaths)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 从 origin 推断存储库信息
+	candidates := make(map[string]adoptCandidate)
+	negatives := strings.Builder{}
+	negatives.WriteString(color.Sprintf("%s Adopt repository from %s: %d unmanaged\n", general.InfoText("INFO:"), general.PrimaryText(config.Storage.Path), len(repoPaths)))
+	for _, repoPath := range repoPaths {
+		relPath, _ := filepath.Rel(config.Storage.Path, repoPath)
+		_, repo, _ := general.IsLocalRepo(repoPath)
+		originUrl := general.GetRepoOriginUrl(repo)
+		url, username, repoName, err := general.SplitRepoUrl(originUrl)
+		switch {
+		case originUrl == "":
+			negatives.WriteString(color.Sprintf("%s %s: %s\n", general.InfoText("INFO:"), general.FgCyanText(relPath), general.WarnText("no origin, skipped")))
+		case err != nil:
+			negatives.WriteString(color.Sprintf("%s %s: %s\n", general.InfoText("INFO:"), general.FgCyanText(relPath), general.WarnText(err, ", skipped")))
+		default:
+			repoKey, exists := general.FindRepoKey(config, url, username, repoName)
+			switch {
+			case exists:
+				negatives.WriteString(color.Sprintf("%s %s: %s\n", general.InfoText("INFO:"), general.FgCyanText(relPath), general.WarnText(url, "/", username, "/", repoName, " is already configured as ", repoKey, " at another path, skipped")))
+			case repoKey != repoName:
+				// 同名存储库来自其他存储库源或用户，以包含用户名的存储库键纳入管理
+				candidates[relPath] = adoptCandidate{path: repoPath, url: url, username: username, repoName: repoName}
+				negatives.WriteString(color.Sprintf("%s %s: %s %s\n", general.InfoText("INFO:"), general.FgCyanText(relPath), general.SecondaryText(originUrl), general.WarnText("(name ", repoName, " is owned by ", configuredOwner(config, repoName), ", adopt as ", repoKey, ")")))
+			default:
+				candidates[relPath] = adoptCandidate{path: repoPath, url: url, username: username, repoName: repoName}
+				negatives.WriteString(color.Sprintf("%s %s: %s\n", general.InfoText("INFO:"), general.FgCyanText(relPath), general.SecondaryText(originUrl)))
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		color.Print(negatives.String())
+		color.Printf("%s No repository to adopt\n", general.InfoText("INFO:"))
+		return
+	}
+
+	// 让用户选择需要纳入管理的存储库
+	var choices []string
+	for relPath := range candidates {
+		choices = append(choices, relPath)
+	}
+	sort.Strings(choices)
+	selectedPaths, err := general.MultipleSelectionFilter(choices, []string{}, negatives.String())
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	if len(selectedPaths) == 0 {
+		return
+	}
+	sort.Strings(selectedPaths)
+
+	// 留屏信息
+	negatives.WriteString(color.Sprintf("%s Selected: %s\n", general.InfoText("INFO:"), general.FgCyanText(strings.Join(selectedPaths, ", "))))
+	negatives.WriteString(color.Sprintf("%s", strings.Repeat(general.Separator1st, general.SeparatorBaseLength)))
+	color.Println(negatives.String())
+
+	for _, relPath := range selectedPaths {
+		candidate := candidates[relPath]
+		actionPrint := color.Sprintf("%s Adopting %s: ", general.RunFlag, general.FgCyanText(candidate.repoName))
+		if err := adoptRepo(configFile, config, relPath, candidate); err != nil {
+			color.Printf("%s%s %s\n", actionPrint, general.ErrorFlag, general.DangerText(err))
+			continue
+		}
+		color.Printf("%s%s %s\n", actionPrint, general.SuccessFlag, general.SecondaryText(relPath, " ", general.Indicator, " ", candidate.url, "/", candidate.username))
+	}
+}
+
+// adoptRepo 将一个本地存储库添加到配置项和配置文件，并应用远程约定
+//
+// 参数：
+//   - configFile: 配置文件路径
+//   - config: 配置项
+//   - relPath: 存储库相对于存储目录的路径
+//   - candidate: 可以纳入管理的本地存储库
+//
+// 返回：
+//   - 错误信息
+func adoptRepo(configFile string, config *general.Config, relPath string, candidate adoptCandidate) error {
+	// 合并到配置项
+	sourceName := general.FindSourceName(config, candidate.url, candidate.username)
+	newSource := sourceName == ""
+	if newSource {
+		sourceName = general.NewSourceName(candidate.url, candidate.username)
+	}
+	repoKey, added := general.MergeRepo(config, general.SourceConfig{Url: candidate.url, Username: candidate.username}, candidate.repoName, filepath.ToSlash(relPath), "", sourceName)
+	if !added {
+		return fmt.Errorf("Repository %s is already configured", repoKey)
+	}
+
+	// 写入配置文件
+	if newSource {
+		if err := general.AppendTableToConfigFile(configFile, []string{"source", sourceName}, map[string]string{"url": candidate.url, "username": candidate.username}); err != nil {
+			return err
+		}
+	}
+	if err := general.AppendRepoToConfigFile(configFile, repoKey); err != nil {
+		return err
+	}
+	if repoConfig, ok := config.Repo[repoKey]; ok {
+		values := make(map[string]string)
+		if repoConfig.Source != "" {
+			values["source"] = repoConfig.Source
+		}
+		if repoConfig.Path != "" {
+			values["path"] = repoConfig.Path
+		}
+		if err := general.AppendTableToConfigFile(configFile, []string{"repo", repoKey}, values); err != nil {
+			return err
+		}
+	}
+
+	// 应用远程约定
+	_, repo, _ := general.IsLocalRepo(candidate.path)
+	return applyRemoteConventions(config, repo, candidate.path, sourceName, repoKey)
+}
+
+// configuredOwner 获取已配置存储库所在的存储库源地址和用户名
+//
+// 参数：
+//   - config: 配置项
+//   - repoKey: 存储库键
+//
+// 返回：
+//   - '<地址>/<用户名>' 格式的所有者
+func configuredOwner(config *general.Config, repoKey string) string {
+	source := general.GetSources(config)[general.GetRepoSourceName(config, repoKey, general.DefaultSourceName(config))]
+	return source.Url + "/" + source.Username
+}
+
+// applyRemoteConventions 按 curator 的约定设置存储库的远程配置
+//
+//   - origin 使用 'git@<地址>:<用户名>/<存储库名>.git' 格式
+//   - 存储库源有镜像源且还没有 pushurl 时，添加主存储库源和镜像源两个 pushurl
+//
+// 参数：
+//   - config: 配置项
+//   - repo: 本地存储库对象
+//   - repoPath: 本地存储库路径
+//   - sourceName: 存储库源名称
+//   - repoName: 存储库键
+//
+// 返回：
+//   - 错误信息
+func applyRemoteConventions(config *general.Config, repo *git.Repository, repoPath, sourceName, repoName string) error {
+	repoSource := getRepoSource(config, sourceName)
+	originUrl := general.BuildRepoUrl(repoSource["repoSourceUrl"], repoSource["repoSourceUsername"], general.RepoBaseName(repoName))
+	if general.GetRepoOriginUrl(repo) != originUrl {
+		if err := general.SetRepoOriginUrl(repo, originUrl); err != nil {
+			return err
+		}
+	}
+
+	gitConfigFile := filepath.Join(repoPath, ".git", "config")
+	if repoSource["newLink"] != "" && len(general.GetRepoPushUrls(repo)) == 0 && general.FileExist(gitConfigFile) {
+		return general.ModifyGitConfig(gitConfigFile, repoSource["originalLink"], repoSource["newLink"])
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+/*
+File: auth.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 23:24:36
+
+Description: 子命令 'auth' 的实现
+
+- 访问令牌和私钥密码保存在加密的凭据文件中，参见 general.CredentialStore
+*/
+
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/curator/general"
+	cssh "golang.org/x/crypto/ssh"
+)
+
+// AuthLogin 保存存储库源的访问令牌或私钥的密码到凭据文件
+//
+//   - 访问令牌保存前通过存储库托管平台的 API 验证
+//   - 私钥密码保存前通过解密私钥验证
+//
+// 参数：
+//   - configFile: 配置文件路径
+//   - source: 存储库源名称，keyFile 不为空时忽略
+//   - keyFile: 私钥文件路径
+func AuthLogin(configFile, source, keyFile string) {
+	if keyFile != "" {
+		loginKey(keyFile)
+		return
+	}
+
+	config, err := loadConfig(configFile)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	repoSource, ok := general.GetSources(config)[source]
+	if !ok {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Source %s is not configured", source))
+		return
+	}
+
+	token, err := general.ReadPassword(color.Sprintf("Enter token for source '%s': ", general.PrimaryText(source)))
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	defer general.ClearPassword(token)
+	trimmedToken := bytes.TrimSpace(token)
+	if len(trimmedToken) == 0 {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Token is empty"))
+		return
+	}
+
+	// 验证访问令牌，不使用配置文件和凭据文件中已有的访问令牌
+	repoSource.Name, repoSource.Token = "", ""
+	provider, err := general.NewProvider(repoSource)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	provider.Token = bytes.Clone(trimmedToken)
+	defer provider.Close()
+	actionPrint := color.Sprintf("%s Verifying token with %s: ", general.RunFlag, general.FgGreenText(provider.Api))
+	general.WaitSpinner.Prefix = actionPrint
+	general.WaitSpinner.Start()
+	userName, err := provider.CurrentUser()
+	general.WaitSpinner.Stop()
+	color.Printf("%s", actionPrint)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	color.Printf("%s %s\n", general.SuccessText("authenticated as"), general.PrimaryText(userName))
+
+	if err := general.UpdateCredentialStore(func(store *general.CredentialStore) error {
+		general.ClearPassword(store.Tokens[source])
+		store.Tokens[source] = general.Secret(bytes.Clone(trimmedToken))
+		return nil
+	}); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	color.Printf("%s Token for source %s saved to %s\n", general.SuccessFlag, general.PrimaryText(source), general.PrimaryText(general.CredentialFile))
+}
+
+// loginKey 保存私钥的密码到凭据文件
+//
+// 参数：
+//   - keyFile: 私钥文件路径
+func loginKey(keyFile string) {
+	keyFile, err := filepath.Abs(keyFile)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	if !general.FileExist(keyFile) {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Key file %s does not exist", keyFile))
+		return
+	}
+
+	passphrase, err := general.ReadPassword(color.Sprintf("Enter passphrase for key '%s': ", general.PrimaryText(keyFile)))
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	defer general.ClearPassword(passphrase)
+	// 验证密码
+	if err := checkKeyPassphrase(keyFile, passphrase); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Wrong passphrase for key %s: %s", keyFile, err))
+		return
+	}
+
+	if err := general.UpdateCredentialStore(func(store *general.CredentialStore) error {
+		general.ClearPassword(store.Passphrases[keyFile])
+		store.Passphrases[keyFile] = general.Secret(bytes.Clone(passphrase))
+		return nil
+	}); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	color.Printf("%s Passphrase for key %s saved to %s\n", general.SuccessFlag, general.PrimaryText(keyFile), general.PrimaryText(general.CredentialFile))
+}
+
+// AuthLogout 从凭据文件中删除存储库源的访问令牌或私钥的密码
+//
+// 参数：
+//   - source: 存储库源名称，keyFile 不为空时忽略
+//   - keyFile: 私钥文件路径
+func AuthLogout(source, keyFile string) {
+	if !general.FileExist(general.CredentialFile) {
+		color.Printf("%s No credential file found at %s\n", general.InfoText("INFO:"), general.PrimaryText(general.CredentialFile))
+		return
+	}
+
+	item := source
+	if keyFile != "" {
+		absKeyFile, err := filepath.Abs(keyFile)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		item = absKeyFile
+	}
+	if err := general.UpdateCredentialStore(func(store *general.CredentialStore) error {
+		credentials := store.Tokens
+		if keyFile != "" {
+			credentials = store.Passphrases
+		}
+		if _, ok := credentials[item]; !ok {
+			return fmt.Errorf("No credential saved for %s", item)
+		}
+		general.ClearPassword(credentials[item])
+		delete(credentials, item)
+		return nil
+	}); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	color.Printf("%s Credential for %s removed\n", general.SuccessFlag, general.PrimaryText(item))
+}
+
+// AuthList 列出凭据文件中保存的凭据，访问令牌只显示前 4 个字符
+func AuthList() {
+	if !general.FileExist(general.CredentialFile) {
+		color.Printf("%s No credential file found at %s\n", general.InfoText("INFO:"), general.PrimaryText(general.CredentialFile))
+		return
+	}
+	store, err := general.UnlockCredentialStore()
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	defer store.Clear()
+
+	color.Printf("%s Credentials in %s\n", general.InfoText("INFO:"), general.PrimaryText(general.CredentialFile))
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+	printCredentials("Tokens", store.Tokens, func(token general.Secret) string {
+		if len(token) <= 4 {
+			return strings.Repeat("*", 8)
+		}
+		return string(token[:4]) + strings.Repeat("*", 8)
+	})
+	printCredentials("Key passphrases", store.Passphrases, func(general.Secret) string {
+		return strings.Repeat("*", 8)
+	})
+}
+
+// checkKeyPassphrase 通过解密私钥验证密码
+//
+// 参数：
+//   - keyFile: 私钥文件路径
+//   - passphrase: 私钥的密码
+//
+// 返回：
+//   - 错误信息
+func checkKeyPassphrase(keyFile string, passphrase []byte) error {
+	pemBytes, err := os.ReadFile(keyFile)
+	if err != nil {
+		return err
+	}
+	defer general.ClearPassword(pemBytes)
+	_, err = cssh.ParsePrivateKeyWithPassphrase(pemBytes, passphrase)
+	return err
+}
+
+// printCredentials 打印一类凭据
+//
+// 参数：
+//   - title: 凭据类别
+//   - credentials: 凭据名称到凭据的映射
+//   - mask: 遮盖凭据的函数
+func printCredentials(title string, credentials map[string]general.Secret, mask func(general.Secret) string) {
+	color.Printf("%s:\n", general.FgBlueText(title))
+	if len(credentials) == 0 {
+		color.Printf("%s %s\n", general.JoinerFinish, general.SecondaryText("none"))
+		return
+	}
+	names := make([]string, 0, len(credentials))
+	for name := range credentials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for index, name := range names {
+		joiner := general.JoinerIng
+		if index == len(names)-1 {
+			joiner = general.JoinerFinish
+		}
+		color.Printf("%s %s %s\n", joiner, general.PrimaryText(name), general.SecondaryText(mask(credentials[name])))
+	}
+}
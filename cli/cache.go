@@ -0,0 +1,125 @@
+/*
+File: cache.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 09:45:02
+
+Description: 子命令 'cache' 的实现
+*/
+
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/curator/general"
+)
+
+// UpdateCache 遍历创建或更新缓存目录中的裸镜像
+//
+// 参数：
+//   - config: 解析 toml 配置文件得到的配置项
+//   - source: 远端存储库源，支持 'github' 和 'gitea'，默认为 'github'
+func UpdateCache(config *general.Config, source string) {
+	if config.Cache.Path == "" {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Cache path is not configured, please set 'cache.path' in the configuration file"))
+		return
+	}
+
+	// 获取公钥
+	publicKeys, err := general.GetSSHAuth(config)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 显示项排序
+	sort.Strings(config.Git.Repos)
+
+	// 输出基础信息
+	color.Printf("%s Update cache from %s: %d repositories\n", general.InfoText("INFO:"), general.FgGreenText(source), len(config.Git.Repos))
+	color.Printf("%s Cache root: %s\n", general.InfoText("INFO:"), general.PrimaryText(config.Cache.Path))
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+
+	for _, repoName := range config.Git.Repos {
+		actionPrint := color.Sprintf("%s Caching %s: ", general.RunFlag, general.FgCyanText(repoName))
+		general.WaitSpinner.Prefix = actionPrint
+		general.WaitSpinner.Start()
+
+		// 存储库单独指定了存储库源时使用该源，否则使用 source
+		repoSource := getRepoSource(config, general.GetRepoSourceName(config, repoName, source))
+		cacheRepoPath := general.GetCacheRepoPath(config.Cache.Path, repoSource["repoSourceUrl"], repoSource["repoSourceUsername"], general.RepoBaseName(repoName))
+		err := general.UpdateCacheRepo(cacheRepoPath, repoSource["repoSourceUrl"], repoSource["repoSourceUsername"], general.RepoBaseName(repoName), publicKeys)
+		general.WaitSpinner.Stop()
+		if err != nil {
+			color.Printf("%s", actionPrint)
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		} else {
+			color.Printf("%s%s %s\n", actionPrint, general.SuccessFlag, general.SecondaryText(cacheRepoPath))
+		}
+
+		// 添加一个延时，使输出更加顺畅
+		general.Delay(general.DelayTime)
+	}
+}
+
+// GcCache 删除缓存目录中不再属于任何已配置存储库的裸镜像
+//
+//   - 仍被本地存储库通过 alternates 引用的裸镜像不会被删除，否则这些存储库会丢失对象
+//
+// 参数：
+//   - config: 解析 toml 配置文件得到的配置项
+func GcCache(config *general.Config) {
+	if config.Cache.Path == "" {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Cache path is not configured, please set 'cache.path' in the configuration file"))
+		return
+	}
+
+	// 所有存储库源下已配置存储库对应的裸镜像路径
+	var keepRepos []string
+	for _, source := range []string{"github", "gitea"} {
+		for _, repoName := range config.Git.Repos {
+			repoSource := getRepoSource(config, general.GetRepoSourceName(config, repoName, source))
+			keepRepos = append(keepRepos, general.GetCacheRepoPath(config.Cache.Path, repoSource["repoSourceUrl"], repoSource["repoSourceUsername"], general.RepoBaseName(repoName)))
+		}
+	}
+	// 本地存储库通过 alternates 引用的裸镜像
+	for _, repoName := range config.Git.Repos {
+		for _, alternate := range general.GetRepoAlternates(general.GetRepoPath(config, repoName)) {
+			keepRepos = append(keepRepos, filepath.Dir(alternate))
+		}
+	}
+
+	cacheRepos, err := general.ListCacheRepos(config.Cache.Path)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	removedNum := 0
+	for _, cacheRepo := range cacheRepos {
+		if slices.Contains(keepRepos, cacheRepo) {
+			continue
+		}
+		if err := general.DeleteFile(cacheRepo); err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			continue
+		}
+		general.DeleteEmptyParents(cacheRepo, config.Cache.Path)
+		color.Printf("%s Removed %s\n", general.SuccessFlag, general.SecondaryText(cacheRepo))
+		removedNum++
+	}
+
+	color.Printf("%s %d/%d cached mirrors removed\n", general.InfoText("INFO:"), removedNum, len(cacheRepos))
+}
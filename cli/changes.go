@@ -0,0 +1,176 @@
+/*
+File: changes.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 00:36:05
+
+Description: 子命令 'changes' 的实现
+*/
+
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/gookit/color"
+	"github.com/yhyj/curator/general"
+)
+
+// ShowChanges 列出一段时间内拉取到各存储库的提交和文件变更统计
+//
+//   - 同一存储库的多次拉取合并为从最早的拉取前提交到最新的拉取后提交的变更
+//
+// 参数：
+//   - since: 起始时间，参见 general.ParseSince
+func ShowChanges(since string) {
+	sinceTime, err := general.ParseSince(since)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	records, err := general.ReadPullRecords(general.HistoryFile, sinceTime)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	color.Printf("%s Changes pulled since %s\n", general.InfoText("INFO:"), general.PrimaryText(sinceTime.Format(time.DateTime)))
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+	if len(records) == 0 {
+		color.Printf("%s %s\n", general.FgBlueText(general.LatestFlag), general.SecondaryText("Nothing pulled"))
+		return
+	}
+
+	// 合并同一存储库的拉取记录
+	merged := make(map[string]general.PullRecord)
+	for _, record := range records {
+		if mergedRecord, ok := merged[record.Path]; ok {
+			mergedRecord.Right = record.Right
+			merged[record.Path] = mergedRecord
+		} else {
+			merged[record.Path] = record
+		}
+	}
+	paths := make([]string, 0, len(merged))
+	for path := range merged {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return merged[paths[i]].Repo < merged[paths[j]].Repo
+	})
+
+	for _, path := range paths {
+		record := merged[path]
+		color.Printf("%s %s: %s --> %s %s\n", general.RunFlag, general.FgCyanText(record.Repo), general.FgBlueText(record.Left[:6]), general.FgGreenText(record.Right[:6]), general.SecondaryText("(", general.RelativeTime(record.Time), ")"))
+
+		leftCommit, rightCommit, err := openRecordCommits(record)
+		if err == nil {
+			err = printChanges(leftCommit, rightCommit)
+		}
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s%s %s %s %s\n", changesIndent(), general.JoinerFinish, general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		}
+	}
+}
+
+// openRecordCommits 打开拉取记录对应的存储库，获取拉取前后的提交
+//
+// 参数：
+//   - record: 拉取记录
+//
+// 返回：
+//   - 拉取前的提交
+//   - 拉取后的提交
+//   - 错误信息
+func openRecordCommits(record general.PullRecord) (*object.Commit, *object.Commit, error) {
+	repo, err := git.PlainOpen(record.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Open %s: %s", record.Path, err)
+	}
+	leftCommit, err := repo.CommitObject(plumbing.NewHash(record.Left))
+	if err != nil {
+		return nil, nil, fmt.Errorf("Commit %s: %s", record.Left[:6], err)
+	}
+	rightCommit, err := repo.CommitObject(plumbing.NewHash(record.Right))
+	if err != nil {
+		return nil, nil, fmt.Errorf("Commit %s: %s", record.Right[:6], err)
+	}
+	return leftCommit, rightCommit, nil
+}
+
+// recordPull 追加拉取记录，失败时只输出错误
+//
+// 参数：
+//   - name: 存储库名
+//   - path: 本地存储库路径
+//   - leftCommit: 拉取前的提交
+//   - rightCommit: 拉取后的提交
+func recordPull(name, path string, leftCommit, rightCommit *object.Commit) {
+	if leftCommit.Hash == rightCommit.Hash {
+		return
+	}
+	record := general.PullRecord{
+		Time:  time.Now(),
+		Repo:  name,
+		Path:  path,
+		Left:  leftCommit.Hash.String(),
+		Right: rightCommit.Hash.String(),
+	}
+	if err := general.AppendPullRecord(general.HistoryFile, record); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s%s %s %s %s\n", changesIndent(), general.JoinerFinish, general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+	}
+}
+
+// printChanges 输出两个提交之间的新增提交（短哈希、作者、标题、相对时间）和文件变更统计
+//
+// 参数：
+//   - leftCommit: 旧提交
+//   - rightCommit: 新提交
+//
+// 返回：
+//   - 错误信息
+func printChanges(leftCommit, rightCommit *object.Commit) error {
+	commits, err := general.CommitsBetween(leftCommit, rightCommit)
+	if err != nil {
+		return err
+	}
+	fileStats, err := general.DiffStat(leftCommit, rightCommit)
+	if err != nil {
+		return err
+	}
+
+	indent := changesIndent()
+	for _, commit := range commits {
+		color.Printf("%s%s %s %s %s %s\n", indent, general.JoinerIng, general.FgBlueText(commit.Hash.String()[:6]), general.FgMagentaText(commit.Author.Name), general.CommitSubject(commit), general.SecondaryText("(", general.RelativeTime(commit.Author.When), ")"))
+	}
+	nameLength := 0 // 文件名最大长度，用于对齐
+	for _, fileStat := range fileStats {
+		nameLength = max(nameLength, len(fileStat.Name))
+	}
+	addition, deletion := 0, 0 // 新增和删除的总行数
+	for _, fileStat := range fileStats {
+		addition += fileStat.Addition
+		deletion += fileStat.Deletion
+		color.Printf("%s%s %-*s | %s %s\n", indent, general.JoinerIng, nameLength, fileStat.Name, general.FgGreenText("+", fileStat.Addition), general.FgRedText("-", fileStat.Deletion))
+	}
+	color.Printf("%s%s %s\n", indent, general.JoinerFinish, general.SecondaryText(len(commits), " commits, ", len(fileStats), " files changed, ", addition, " insertions(+), ", deletion, " deletions(-)"))
+	return nil
+}
+
+// changesIndent 获取变更列表的缩进
+//
+// 返回：
+//   - 与存储库操作提示对齐的缩进
+func changesIndent() string {
+	return strings.Repeat(" ", len(general.RunFlag)+len("Pulling"))
+}
@@ -10,11 +10,13 @@ Description: 子命令 'clone' 的实现
 package cli
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/go-git/go-git/v5"
 	"github.com/gookit/color"
 	"github.com/yhyj/curator/general"
 )
@@ -26,39 +28,13 @@ import (
 //   - source: 远端存储库源，支持 'github' 和 'gitea'，默认为 'github'
 func RollingCloneRepos(config *general.Config, source string) {
 	// 确定存储库源
-	githubLink := config.Git.GithubUrl + ":" + config.Git.GithubUsername
-	giteaLink := config.Git.GiteaUrl + ":" + config.Git.GiteaUsername
-	repoSource := func() map[string]string {
-		switch source {
-		case "github":
-			return map[string]string{
-				"repoSourceUrl":      config.Git.GithubUrl,
-				"repoSourceUsername": config.Git.GithubUsername,
-				"originalLink":       githubLink,
-				"newLink":            giteaLink,
-			}
-		case "gitea":
-			return map[string]string{
-				"repoSourceUrl":      config.Git.GiteaUrl,
-				"repoSourceUsername": config.Git.GiteaUsername,
-				"originalLink":       giteaLink,
-				"newLink":            githubLink,
-			}
-		default:
-			return map[string]string{
-				"repoSourceUrl":      config.Git.GithubUrl,
-				"repoSourceUsername": config.Git.GithubUsername,
-				"originalLink":       githubLink,
-				"newLink":            giteaLink,
-			}
-		}
-	}()
+	repoSource := getRepoSource(config, source)
 
 	// 为已存在的本地存储库计数
 	totalNum := len(config.Git.Repos) // 总存储库数
 	clonedRepo := make([]string, 0)   // 已 Clone 存储库
 	for _, repoName := range config.Git.Repos {
-		repoPath := filepath.Join(config.Storage.Path, repoName) // 本地存储库路径
+		repoPath := general.GetRepoPath(config, repoName) // 本地存储库路径
 		if general.FileExist(repoPath) {
 			isRepo, _, _ := general.IsLocalRepo(repoPath)
 			if isRepo {
@@ -73,7 +49,7 @@ func RollingCloneRepos(config *general.Config, source string) {
 	// 输出基础信息
 	negatives := strings.Builder{}
 	negatives.WriteString(color.Sprintf("%s Clone repository from %s, %d/%d cloned\n", general.InfoText("INFO:"), general.FgGreenText(source), len(clonedRepo), totalNum))
-	negatives.WriteString(color.Sprintf("%s Repository root: %s\n", general.InfoText("INFO:"), general.PrimaryText(config.Storage.Path)))
+	negatives.WriteString(color.Sprintf("%s Repository root: %s (%s)\n", general.InfoText("INFO:"), general.PrimaryText(config.Storage.Path), general.FgGreenText(storageMode(config))))
 
 	// 让用户选择需要 Clone 的存储库
 	selectedRepos, err := general.MultipleSelectionFilter(config.Git.Repos, clonedRepo, negatives.String())
@@ -96,10 +72,14 @@ func RollingCloneRepos(config *general.Config, source string) {
 	// 遍历所选存储库名
 	for _, repoName := range selectedRepos {
 		// 构建本地存储库路径
-		repoPath := filepath.Join(config.Storage.Path, repoName)
+		repoPath := general.GetRepoPath(config, repoName)
 
-		// Clone
-		clone(config, repoSource, repoPath, repoName, config.Script.RunQueue)
+		// Clone，存储库单独指定了存储库源时使用该源
+		if repoConfig, ok := config.Repo[repoName]; ok && repoConfig.Source != "" {
+			clone(config, getRepoSource(config, repoConfig.Source), repoPath, repoName, config.Script.RunQueue)
+		} else {
+			clone(config, repoSource, repoPath, repoName, config.Script.RunQueue)
+		}
 
 		// 添加一个延时，使输出更加顺畅
 		general.Delay(general.DelayTime)
@@ -112,11 +92,17 @@ func RollingCloneRepos(config *general.Config, source string) {
 //   - config: 配置项
 //   - source: 存储库源
 //   - path: 本地存储库路径
-//   - name: 存储库名
+//   - name: 存储库键
 //   - scripts: Clone 完成后需要执行的脚本
 func clone(config *general.Config, source map[string]string, path, name string, scripts []string) {
+	// 检测存储模式，避免拼写错误时静默使用工作树模式
+	if err := general.CheckStorageMode(config.Storage.Mode); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
 	// 获取公钥
-	publicKeys, err := general.GetPublicKeysByGit(config.SSH.RsaFile)
+	publicKeys, err := general.GetSSHAuth(config)
 	if err != nil {
 		fileName, lineNo := general.GetCallerInfo()
 		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
@@ -156,8 +142,46 @@ func clone(config *general.Config, source map[string]string, path, name string,
 		}
 	}
 
-	// 开始 Clone
-	repo, err := general.CloneRepoViaSSH(path, source["repoSourceUrl"], source["repoSourceUsername"], name, publicKeys)
+	// 镜像模式只创建裸镜像存储库，跳过创建本地分支、处理子模块和运行脚本等依赖工作树的操作
+	if config.Storage.Mode == general.MirrorMode {
+		repo, err := general.MirrorRepoViaSSH(path, source["repoSourceUrl"], source["repoSourceUsername"], general.RepoBaseName(name), publicKeys)
+		general.WaitSpinner.Stop()
+		if err != nil {
+			color.Printf("%s", actionPrint)
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		branchNames, err := general.GetRepoBranchNames(repo)
+		if err != nil {
+			color.Printf("%s", actionPrint)
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		color.Printf("%s%s %s\n", actionPrint, general.SuccessFlag, general.SecondaryText("[", strings.Join(branchNames, " "), "]"))
+		return
+	}
+
+	// 开始 Clone，配置了缓存目录时优先从缓存 Clone，失败则直接从远端 Clone
+	var repo *git.Repository
+	if config.Cache.Path != "" {
+		cacheRepoPath := general.GetCacheRepoPath(config.Cache.Path, source["repoSourceUrl"], source["repoSourceUsername"], general.RepoBaseName(name))
+		repo, err = general.CloneRepoViaCache(path, cacheRepoPath, source["repoSourceUrl"], source["repoSourceUsername"], general.RepoBaseName(name), publicKeys)
+		if err != nil {
+			// 清理不完整的 Clone，清理失败时无法再直接从远端 Clone
+			if deleteErr := general.DeleteFile(path); deleteErr != nil {
+				general.WaitSpinner.Stop()
+				color.Printf("%s", actionPrint)
+				fileName, lineNo := general.GetCallerInfo()
+				color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Clone via cache: %s, remove incomplete clone: %s", err, deleteErr))
+				return
+			}
+		}
+	}
+	if config.Cache.Path == "" || err != nil {
+		repo, err = general.CloneRepoViaSSH(path, source["repoSourceUrl"], source["repoSourceUsername"], general.RepoBaseName(name), publicKeys)
+	}
 
 	// Clone 结束
 	if err != nil { // Clone 失败
@@ -189,10 +213,12 @@ func clone(config *general.Config, source map[string]string, path, name string,
 			}
 		}
 
-		// 更新主存储库的配置文件 .git/config
-		configFile := filepath.Join(path, ".git", "config")
-		if err = general.ModifyGitConfig(configFile, source["originalLink"], source["newLink"]); err != nil {
-			errList = append(errList, "Update local repository git config: "+err.Error())
+		// 更新主存储库的配置文件 .git/config，没有镜像源时无需添加 pushurl
+		if source["newLink"] != "" {
+			configFile := filepath.Join(path, ".git", "config")
+			if err = general.ModifyGitConfig(configFile, source["originalLink"], source["newLink"]); err != nil {
+				errList = append(errList, "Update local repository git config: "+err.Error())
+			}
 		}
 
 		// 获取主存储库的 worktree
@@ -209,6 +235,13 @@ func clone(config *general.Config, source map[string]string, path, name string,
 		otherErrList := general.CreateLocalBranch(repo, remoteBranchs)
 		errList = append(errList, otherErrList...)
 
+		// 存储库指定了 revision 时切换到该分支或检出到其指向的提交
+		if repoConfig, ok := config.Repo[name]; ok && repoConfig.Revision != "" {
+			if err := general.CheckoutRevision(repo, repoConfig.Revision, publicKeys); err != nil {
+				errList = append(errList, "Checkout to revision "+repoConfig.Revision+": "+err.Error())
+			}
+		}
+
 		// 获取主存储库的本地分支信息
 		var localBranchStr []string
 		localBranchs, err := general.GetRepoBranchInfo(worktree, false, "", "local")
@@ -240,9 +273,11 @@ func clone(config *general.Config, source map[string]string, path, name string,
 			isRepo, submoduleRepo, _ := general.IsLocalRepo(submodule.Config().Path)
 			if isRepo {
 				// 更新子存储库的配置文件 .git/modules/<submoduleName>/config
-				configFile := filepath.Join(path, ".git", "modules", submodule.Config().Name, "config")
-				if err = general.ModifyGitConfig(configFile, source["originalLink"], source["newLink"]); err != nil {
-					errList = append(errList, "Update local submodule repository git config: "+err.Error())
+				if source["newLink"] != "" {
+					configFile := filepath.Join(path, ".git", "modules", submodule.Config().Name, "config")
+					if err = general.ModifyGitConfig(configFile, source["originalLink"], source["newLink"]); err != nil {
+						errList = append(errList, "Update local submodule repository git config: "+err.Error())
+					}
 				}
 
 				// 获取子模块的远程分支信息
@@ -291,3 +326,47 @@ func clone(config *general.Config, source map[string]string, path, name string,
 		}
 	}
 }
+
+// storageMode 获取存储模式，未配置时为工作树模式
+//
+// 参数：
+//   - config: 配置项
+//
+// 返回：
+//   - 存储模式
+func storageMode(config *general.Config) string {
+	if config.Storage.Mode == "" {
+		return general.WorktreeMode
+	}
+	return config.Storage.Mode
+}
+
+// getRepoSource 根据存储库源名称获取存储库源信息
+//
+// 参数：
+//   - config: 配置项
+//   - source: 远端存储库源，支持 'github'、'gitea' 和 [source] 中自定义的存储库源，默认为 'github'
+//
+// 返回：
+//   - 存储库源信息，包括地址、用户名以及用于修改 pushurl 的原始链接和新链接（没有镜像源时为空）
+func getRepoSource(config *general.Config, source string) map[string]string {
+	sources := general.GetSources(config)
+	repoSource, ok := sources[source]
+	if !ok {
+		source, repoSource = "github", sources["github"]
+	}
+
+	// 镜像源
+	newLink := ""
+	if mirrorSources := general.GetMirrorSourceNames(config, source); len(mirrorSources) > 0 {
+		mirrorSource := sources[mirrorSources[0]]
+		newLink = mirrorSource.Url + ":" + mirrorSource.Username
+	}
+
+	return map[string]string{
+		"repoSourceUrl":      repoSource.Url,
+		"repoSourceUsername": repoSource.Username,
+		"originalLink":       repoSource.Url + ":" + repoSource.Username,
+		"newLink":            newLink,
+	}
+}
@@ -0,0 +1,206 @@
+/*
+File: convert.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 14:02:51
+
+Description: 子命令 'convert' 的实现
+*/
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/curator/general"
+)
+
+var convertFormats = []string{"curator", "repo", "vcstool", "mr"} // 支持转换的格式
+
+// ConvertConfig 在 curator 配置文件、repo 清单、vcstool 的 .repos 文件和 myrepos 的 .mrconfig 文件之间转换
+//
+//   - 转换为 curator 格式时合并到目标配置文件中，其他格式输出到文件或标准输出
+//
+// 参数：
+//   - configFile: 配置文件路径
+//   - from: 源格式
+//   - to: 目标格式
+//   - input: 源文件路径，源格式为 curator 时默认为配置文件
+//   - output: 目标文件路径，目标格式为 curator 时默认为配置文件，其他格式默认输出到标准输出
+//   - source: 导出时存储库没有指定存储库源时使用的存储库源
+func ConvertConfig(configFile, from, to, input, output, source string) {
+	// 检查参数
+	if !slices.Contains(convertFormats, from) || !slices.Contains(convertFormats, to) {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Unsupported format, optional values are %s", strings.Join(convertFormats, ", ")))
+		return
+	}
+	if from == to {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Source and target formats are the same"))
+		return
+	}
+	if from != "curator" && input == "" {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Please specify the %s file with --input", from))
+		return
+	}
+
+	// 准备配置项：源格式为 curator 时读取源配置文件，目标格式为 curator 时读取目标配置文件用于合并
+	config := &general.Config{}
+	switch {
+	case from == "curator":
+		if input == "" {
+			input = configFile
+		}
+		loadedConfig, err := loadConfig(input)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		config = loadedConfig
+	case to == "curator":
+		if output == "" {
+			output = configFile
+		}
+		loadedConfig, err := loadConfig(output)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		config = loadedConfig
+	}
+
+	// 导入
+	if from != "curator" {
+		addedRepos, err := importConfig(config, from, input)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		if to == "curator" {
+			color.Printf("%s Import %s: %d new repositories\n", general.InfoText("INFO:"), general.PrimaryText(input), len(addedRepos))
+			if len(addedRepos) > 0 {
+				color.Printf("%s Added: %s\n", general.InfoText("INFO:"), general.FgCyanText(strings.Join(addedRepos, ", ")))
+			}
+		}
+	}
+
+	// 导出
+	if to == "curator" {
+		if err := general.SaveConfigChanges(output, config); err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		color.Printf("Update %s: %s\n", general.PrimaryText(output), general.SuccessText("file updated"))
+		return
+	}
+	if output == "" {
+		if err := exportConfig(os.Stdout, config, to, source); err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		}
+		return
+	}
+	if err := general.CreateFile(output); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	file, err := os.OpenFile(output, os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	defer file.Close()
+	if err := exportConfig(file, config, to, source); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	color.Printf("Export %s: %s\n", general.PrimaryText(output), general.SuccessText(len(config.Git.Repos), " repositories written"))
+}
+
+// loadConfig 读取配置文件并加载到结构体
+//
+// 参数：
+//   - configFile: 配置文件路径
+//
+// 返回：
+//   - 配置项
+//   - 错误信息
+func loadConfig(configFile string) (*general.Config, error) {
+	configTree, err := general.GetTomlConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+	return general.LoadConfigToStruct(configTree)
+}
+
+// importConfig 读取指定格式的文件并合并到配置项中
+//
+// 参数：
+//   - config: 配置项
+//   - format: 文件格式
+//   - input: 文件路径
+//
+// 返回：
+//   - 新增的存储库名
+//   - 错误信息
+func importConfig(config *general.Config, format, input string) ([]string, error) {
+	switch format {
+	case "repo":
+		manifest, err := general.ReadManifest(input)
+		if err != nil {
+			return nil, err
+		}
+		return general.ImportManifest(config, manifest, "")
+	case "vcstool":
+		vcsRepos, err := general.ReadVcsRepos(input)
+		if err != nil {
+			return nil, err
+		}
+		return general.ImportVcsRepos(config, vcsRepos)
+	case "mr":
+		mrConfig, err := general.ReadMrConfig(input)
+		if err != nil {
+			return nil, err
+		}
+		return general.ImportMrConfig(config, mrConfig)
+	default:
+		return nil, fmt.Errorf("Unsupported format: %s", format)
+	}
+}
+
+// exportConfig 将配置项以指定格式写入 writer
+//
+// 参数：
+//   - writer: 写入目标
+//   - config: 配置项
+//   - format: 文件格式
+//   - source: 存储库没有指定存储库源时使用的存储库源
+//
+// 返回：
+//   - 错误信息
+func exportConfig(writer io.Writer, config *general.Config, format, source string) error {
+	switch format {
+	case "repo":
+		return general.WriteManifest(writer, general.ExportManifest(config, source))
+	case "vcstool":
+		return general.WriteVcsRepos(writer, general.ExportVcsRepos(config, source))
+	case "mr":
+		return general.WriteMrConfig(writer, general.ExportMrConfig(config, source))
+	default:
+		return fmt.Errorf("Unsupported format: %s", format)
+	}
+}
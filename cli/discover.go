@@ -0,0 +1,165 @@
+/*
+File: discover.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 15:12:40
+
+Description: 子命令 'discover' 的实现
+*/
+
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/curator/general"
+)
+
+// DiscoverRepos 通过存储库托管平台的 API 发现存储库，由用户选择后写回配置文件
+//
+//   - 选择器中列出新增（平台上有、配置中没有）和已删除（配置中有、平台上没有）的存储库，其中已删除的存储库高亮显示
+//   - 选中的新增存储库会被添加到配置中，选中的已删除存储库会从配置中移除
+//
+// 参数：
+//   - configFile: 配置文件路径
+//   - source: 存储库源名称
+func DiscoverRepos(configFile, source string) {
+	config, err := loadConfig(configFile)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 创建存储库托管平台对象
+	repoSource, ok := general.GetSources(config)[source]
+	if !ok {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Source %s is not configured", source))
+		return
+	}
+	provider, err := general.NewProvider(repoSource)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	defer provider.Close()
+
+	// 获取平台上的存储库
+	actionPrint := color.Sprintf("%s Querying %s: ", general.RunFlag, general.FgGreenText(provider.Api))
+	general.WaitSpinner.Prefix = actionPrint
+	general.WaitSpinner.Start()
+	remoteRepos, err := provider.ListRepos()
+	general.WaitSpinner.Stop()
+	if err != nil {
+		color.Printf("%s", actionPrint)
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	var remoteNames []string
+	for _, remoteRepo := range remoteRepos {
+		remoteNames = append(remoteNames, remoteRepo.Name)
+	}
+
+	// 对比配置
+	var newRepos, removedRepos []string
+	for _, remoteName := range remoteNames {
+		// 已有来自其他存储库源或用户的同名存储库时使用包含用户名的存储库键
+		if repoKey, exists := general.FindRepoKey(config, repoSource.Url, repoSource.Username, remoteName); !exists {
+			newRepos = append(newRepos, repoKey)
+		}
+	}
+	for _, repoName := range config.Git.Repos {
+		if belongsToSource(config, repoName, source) && !slices.Contains(remoteNames, general.RepoBaseName(repoName)) {
+			removedRepos = append(removedRepos, repoName)
+		}
+	}
+	sort.Strings(newRepos)
+	sort.Strings(removedRepos)
+
+	if len(newRepos) == 0 && len(removedRepos) == 0 {
+		color.Printf("%s%s %s\n", actionPrint, general.FgBlueText(general.LatestFlag), general.SecondaryText("Configuration is up to date with ", source, " (", len(remoteNames), " repositories)"))
+		return
+	}
+
+	// 输出基础信息
+	negatives := strings.Builder{}
+	negatives.WriteString(color.Sprintf("%s Discover repository from %s: %d remote, %d new, %d removed\n", general.InfoText("INFO:"), general.FgGreenText(source), len(remoteNames), len(newRepos), len(removedRepos)))
+	if len(newRepos) > 0 {
+		negatives.WriteString(color.Sprintf("%s New: %s\n", general.InfoText("INFO:"), general.FgCyanText(strings.Join(newRepos, ", "))))
+	}
+	if len(removedRepos) > 0 {
+		negatives.WriteString(color.Sprintf("%s Removed: %s %s\n", general.InfoText("INFO:"), general.FgMagentaText(strings.Join(removedRepos, ", ")), general.SecondaryText("(", general.NiceFlag, ")")))
+	}
+	negatives.WriteString(color.Sprintf("%s Selected new repositories will be added, selected removed ones will be dropped\n", general.InfoText("INFO:")))
+
+	// 让用户选择
+	choices := append(slices.Clone(newRepos), removedRepos...)
+	sort.Strings(choices)
+	selectedRepos, err := general.MultipleSelectionFilter(choices, removedRepos, negatives.String())
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	if len(selectedRepos) == 0 {
+		return
+	}
+	sort.Strings(selectedRepos)
+
+	// 留屏信息
+	negatives.WriteString(color.Sprintf("%s Selected: %s\n", general.InfoText("INFO:"), general.FgCyanText(strings.Join(selectedRepos, ", "))))
+	negatives.WriteString(color.Sprintf("%s", strings.Repeat(general.Separator1st, general.SeparatorBaseLength)))
+	color.Println(negatives.String())
+
+	// 更新配置
+	for _, repoName := range selectedRepos {
+		if slices.Contains(newRepos, repoName) {
+			config.Git.Repos = append(config.Git.Repos, repoName)
+			if source != "github" && source != "gitea" {
+				if config.Repo == nil {
+					config.Repo = make(map[string]general.RepoConfig)
+				}
+				repoConfig := config.Repo[repoName]
+				repoConfig.Source = source
+				config.Repo[repoName] = repoConfig
+			}
+			color.Printf("%s Add %s\n", general.SuccessFlag, general.FgCyanText(repoName))
+		} else {
+			config.Git.Repos = slices.DeleteFunc(config.Git.Repos, func(name string) bool { return name == repoName })
+			delete(config.Repo, repoName)
+			color.Printf("%s Drop %s\n", general.SuccessFlag, general.FgMagentaText(repoName))
+		}
+	}
+	if err := general.SaveConfigChanges(configFile, config); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	color.Printf("Update %s: %s\n", general.PrimaryText(configFile), general.SuccessText("file updated"))
+}
+
+// belongsToSource 判断存储库是否属于指定存储库源
+//
+//   - 没有单独指定存储库源的存储库同时属于 github 和 gitea
+//
+// 参数：
+//   - config: 配置项
+//   - repoName: 存储库名
+//   - source: 存储库源名称
+//
+// 返回：
+//   - 属于返回 true，否则返回 false
+func belongsToSource(config *general.Config, repoName, source string) bool {
+	repoSource := general.GetRepoSourceName(config, repoName, "")
+	if repoSource == "" {
+		return source == "github" || source == "gitea"
+	}
+	return repoSource == source
+}
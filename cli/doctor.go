@@ -0,0 +1,420 @@
+/*
+File: doctor.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 20:13:48
+
+Description: 子命令 'doctor' 的实现
+*/
+
+package cli
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/gookit/color"
+	"github.com/yhyj/curator/general"
+)
+
+// doctorIssue 检查发现的问题
+type doctorIssue struct {
+	item   string // 问题对象
+	detail string // 问题描述
+	fix    string // 修复建议
+}
+
+// CheckInventory 对比存储目录和配置文件，报告孤立的目录和配置项
+//
+//   - 存储目录中不在配置文件里的本地存储库，以及不属于任何存储库的目录
+//   - 配置文件中从未 Clone 的存储库
+//   - 会阻止 Clone 的非空非存储库目录
+//   - origin 指向非预期存储库源的存储库
+//
+// 参数：
+//   - config: 解析 toml 配置文件得到的配置项
+func CheckInventory(config *general.Config) {
+	repoNames := slices.Clone(config.Git.Repos)
+	sort.Strings(repoNames)
+
+	// 已配置存储库的路径
+	repoPaths := make(map[string]string)
+	skipPaths := []string{config.Cache.Path}
+	for _, repoName := range repoNames {
+		repoPaths[repoName] = general.GetRepoPath(config, repoName)
+		skipPaths = append(skipPaths, repoPaths[repoName])
+	}
+
+	// 输出基础信息
+	color.Printf("%s Inventory of %s: %d configured repositories\n", general.InfoText("INFO:"), general.PrimaryText(config.Storage.Path), len(repoNames))
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+
+	var notCloned, blocked, unexpected []doctorIssue
+	for _, repoName := range repoNames {
+		repoPath := repoPaths[repoName]
+		if !general.FileExist(repoPath) {
+			notCloned = append(notCloned, doctorIssue{item: repoName, detail: repoPath, fix: "run 'curator clone' and select it, or remove it from git.repos"})
+			continue
+		}
+		isRepo, repo, _ := general.IsLocalRepo(repoPath)
+		if !isRepo {
+			if !general.FolderEmpty(repoPath) {
+				blocked = append(blocked, doctorIssue{item: repoName, detail: repoPath + " is not a local repository and not empty", fix: "move or delete the folder, then run 'curator clone'"})
+			}
+			continue
+		}
+		if issue, ok := checkOrigin(config, repo, repoName, repoPath); !ok {
+			unexpected = append(unexpected, issue)
+		}
+	}
+
+	// 存储目录中不在配置文件里的存储库和目录
+	var unmanaged []doctorIssue
+	unmanagedPaths, err := general.FindLocalRepos(config.Storage.Path, skipPaths)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	for _, unmanagedPath := range unmanagedPaths {
+		_, repo, _ := general.IsLocalRepo(unmanagedPath)
+		unmanaged = append(unmanaged, doctorIssue{item: relativePath(config.Storage.Path, unmanagedPath), detail: "origin " + general.GetRepoOriginUrl(repo), fix: "run 'curator adopt'"})
+	}
+	for _, strayPath := range findStrayDirs(config.Storage.Path, append(skipPaths, unmanagedPaths...)) {
+		unmanaged = append(unmanaged, doctorIssue{item: relativePath(config.Storage.Path, strayPath), detail: "not a repository and not used by any repository", fix: "move or delete it"})
+	}
+
+	// 输出检查结果
+	issueNum := 0
+	issueNum += printDoctorCheck("Unmanaged directories", unmanaged)
+	issueNum += printDoctorCheck("Repositories never cloned", notCloned)
+	issueNum += printDoctorCheck("Folders blocking clone", blocked)
+	issueNum += printDoctorCheck("Unexpected origins", unexpected)
+	color.Printf("%s %d issues found\n", general.InfoText("INFO:"), issueNum)
+}
+
+// DiagnoseEnvironment 诊断运行环境和身份认证，定位 Clone 失败的原因
+//
+//   - 配置文件能否解析
+//   - 各私钥文件是否存在、权限是否安全、能否加载
+//   - known_hosts 中是否有各存储库源的主机公钥（固定了主机公钥指纹的除外）
+//   - 各存储库源能否解析、连接并接受私钥
+//   - 存储目录是否可写
+//
+// 参数：
+//   - configFile: 配置文件路径
+func DiagnoseEnvironment(configFile string) {
+	color.Printf("%s Diagnosing environment with %s\n", general.InfoText("INFO:"), general.PrimaryText(configFile))
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+
+	// 配置文件
+	var configIssues []doctorIssue
+	config, err := func() (*general.Config, error) {
+		configTree, err := general.GetTomlConfig(configFile)
+		if err != nil {
+			return nil, err
+		}
+		return general.LoadConfigToStruct(configTree)
+	}()
+	if err != nil {
+		configIssues = append(configIssues, doctorIssue{item: configFile, detail: err.Error(), fix: "fix the file, or run 'curator config --create' to recreate it"})
+	}
+	issueNum := printDoctorCheck("Configuration file", configIssues)
+	if config == nil {
+		color.Printf("%s %d issues found\n", general.InfoText("INFO:"), issueNum)
+		return
+	}
+
+	// 私钥文件
+	keyIssues, publicKeys := checkKeyFiles(config)
+	issueNum += printDoctorCheck("SSH key files", keyIssues)
+
+	// 存储库源
+	sources := general.GetSources(config)
+	var knownHostsIssues, sourceIssues []doctorIssue
+	for _, sourceName := range sortedSourceNames(sources) {
+		host := sources[sourceName].Url
+		if issue, ok := checkKnownHost(sourceName, sources[sourceName]); !ok {
+			knownHostsIssues = append(knownHostsIssues, issue)
+		}
+		if issue, ok := checkSourceAccess(sourceName, host, publicKeys); !ok {
+			sourceIssues = append(sourceIssues, issue)
+		}
+	}
+	issueNum += printDoctorCheck("Known hosts", knownHostsIssues)
+	issueNum += printDoctorCheck("Source access", sourceIssues)
+
+	// 存储目录
+	var storageIssues []doctorIssue
+	if err := checkWritable(config.Storage.Path); err != nil {
+		storageIssues = append(storageIssues, doctorIssue{item: config.Storage.Path, detail: err.Error(), fix: "create the folder or fix its permissions, or change storage.path"})
+	}
+	issueNum += printDoctorCheck("Storage path", storageIssues)
+
+	color.Printf("%s %d issues found\n", general.InfoText("INFO:"), issueNum)
+}
+
+// checkKeyFiles 检查配置的私钥文件是否存在、权限是否安全、能否加载
+//
+// 参数：
+//   - config: 配置项
+//
+// 返回：
+//   - 发现的问题
+//   - ssh 公钥，没有可用的私钥时为 nil
+func checkKeyFiles(config *general.Config) ([]doctorIssue, *ssh.PublicKeys) {
+	keyFiles := general.GetIdentityFiles(config)
+	sources := general.GetSources(config)
+	for _, sourceName := range sortedSourceNames(sources) {
+		for _, keyFile := range sources[sourceName].IdentityFiles {
+			if !slices.Contains(keyFiles, keyFile) {
+				keyFiles = append(keyFiles, keyFile)
+			}
+		}
+	}
+	if len(keyFiles) == 0 {
+		return []doctorIssue{{item: "ssh.identity_files", detail: "no SSH key configured", fix: "run 'ssh-keygen -t ed25519', then add the key to ssh.identity_files"}}, nil
+	}
+
+	var issues []doctorIssue
+	for _, keyFile := range keyFiles {
+		fileInfo, err := os.Stat(keyFile)
+		if err != nil {
+			issues = append(issues, doctorIssue{item: keyFile, detail: err.Error(), fix: "run 'ssh-keygen -t ed25519 -f " + keyFile + "', or remove it from identity_files"})
+			continue
+		}
+		if fileInfo.Mode().Perm()&0077 != 0 {
+			issues = append(issues, doctorIssue{item: keyFile, detail: fmt.Sprintf("permissions %04o are too open", fileInfo.Mode().Perm()), fix: "chmod 600 " + keyFile})
+		}
+		if _, err := general.LoadIdentity(keyFile); err != nil {
+			issues = append(issues, doctorIssue{item: keyFile, detail: "unable to load: " + err.Error(), fix: "check the passphrase, or make sure it is a private key"})
+		}
+	}
+
+	publicKeys, err := general.GetSSHAuth(config)
+	if err != nil {
+		issues = append(issues, doctorIssue{item: "ssh.identity_files", detail: err.Error(), fix: "fix the SSH key files above"})
+		return issues, nil
+	}
+	return issues, publicKeys
+}
+
+// checkKnownHost 检查 known_hosts 中是否有存储库源的主机公钥
+//
+//   - 固定了主机公钥指纹（host_keys）的存储库源不使用 known_hosts，直接通过
+//
+// 参数：
+//   - sourceName: 存储库源名称
+//   - source: 存储库源
+//
+// 返回：
+//   - 发现的问题
+//   - 检查通过返回 true，否则返回 false
+func checkKnownHost(sourceName string, source general.SourceConfig) (doctorIssue, bool) {
+	if len(source.HostKeys) > 0 {
+		return doctorIssue{}, true
+	}
+	fix := "ssh-keyscan " + source.Url + " >> ~/.ssh/known_hosts after verifying the fingerprint, or pin it in [source." + sourceName + "].host_keys"
+	known, err := general.IsHostKnown(source.Url)
+	if err != nil {
+		return doctorIssue{item: sourceName, detail: err.Error(), fix: fix}, false
+	}
+	if !known {
+		return doctorIssue{item: sourceName, detail: "no host key for " + source.Url, fix: fix}, false
+	}
+	return doctorIssue{}, true
+}
+
+// checkSourceAccess 检查存储库源能否解析、连接并接受私钥
+//
+// 参数：
+//   - sourceName: 存储库源名称
+//   - host: 存储库源地址
+//   - publicKeys: ssh 公钥，为 nil 时不检查认证
+//
+// 返回：
+//   - 发现的问题
+//   - 检查通过返回 true，否则返回 false
+func checkSourceAccess(sourceName, host string, publicKeys *ssh.PublicKeys) (doctorIssue, bool) {
+	sshHost := general.ResolveSSHHost(host)
+	if len(sshHost.ProxyJump) == 0 { // 经由跳板主机时由跳板主机解析
+		if _, err := net.LookupHost(sshHost.HostName); err != nil {
+			return doctorIssue{item: sourceName, detail: "DNS: " + err.Error(), fix: "check the source url, ~/.ssh/config and your DNS settings"}, false
+		}
+	}
+	if publicKeys == nil {
+		return doctorIssue{item: sourceName, detail: "authentication skipped, the SSH key could not be loaded", fix: "fix the SSH key file first"}, false
+	}
+	if err := general.CheckSSHAuth(host, publicKeys); err != nil {
+		fix := "make sure " + sshHost.Address() + " is reachable from this network, check ~/.ssh/config for " + host
+		if strings.Contains(err.Error(), "unable to authenticate") {
+			fix = "add the public key of the SSH key file to your account on " + host
+		} else if strings.Contains(err.Error(), "knownhosts") || strings.Contains(err.Error(), "known_hosts") {
+			fix = "fix the known hosts issue above"
+		}
+		return doctorIssue{item: sourceName, detail: err.Error(), fix: fix}, false
+	}
+	return doctorIssue{}, true
+}
+
+// sortedSourceNames 获取排序后的存储库源名称
+//
+// 参数：
+//   - sources: 存储库源
+//
+// 返回：
+//   - 存储库源名称
+func sortedSourceNames(sources map[string]general.SourceConfig) []string {
+	var sourceNames []string
+	for sourceName := range sources {
+		sourceNames = append(sourceNames, sourceName)
+	}
+	sort.Strings(sourceNames)
+	return sourceNames
+}
+
+// checkWritable 检查目录是否存在且可写
+//
+// 参数：
+//   - dirPath: 目录路径
+//
+// 返回：
+//   - 错误信息
+func checkWritable(dirPath string) error {
+	fileInfo, err := os.Stat(dirPath)
+	if err != nil {
+		return err
+	}
+	if !fileInfo.IsDir() {
+		return fmt.Errorf("%s is not a folder", dirPath)
+	}
+	probeFile, err := os.CreateTemp(dirPath, ".curator-doctor-*")
+	if err != nil {
+		return err
+	}
+	probeFile.Close()
+	return os.Remove(probeFile.Name())
+}
+
+// checkOrigin 检查存储库的 origin 是否指向其存储库源或镜像源
+//
+// 参数：
+//   - config: 配置项
+//   - repo: 本地存储库对象
+//   - repoName: 存储库键
+//   - repoPath: 本地存储库路径
+//
+// 返回：
+//   - 发现的问题
+//   - origin 符合预期返回 true，否则返回 false
+func checkOrigin(config *general.Config, repo *git.Repository, repoName, repoPath string) (doctorIssue, bool) {
+	sources := general.GetSources(config)
+	sourceName := general.GetRepoSourceName(config, repoName, general.DefaultSourceName(config))
+	expectedSources := append([]string{sourceName}, general.GetMirrorSourceNames(config, sourceName)...)
+
+	originUrl := general.GetRepoOriginUrl(repo)
+	url, username, name, err := general.SplitRepoUrl(originUrl)
+	if err == nil && name == general.RepoBaseName(repoName) {
+		for _, expectedSource := range expectedSources {
+			if sources[expectedSource].Url == url && sources[expectedSource].Username == username {
+				return doctorIssue{}, true
+			}
+		}
+	}
+
+	expectedUrl := general.BuildRepoUrl(sources[sourceName].Url, sources[sourceName].Username, general.RepoBaseName(repoName))
+	return doctorIssue{
+		item:   repoName,
+		detail: "origin " + originUrl + ", expected " + sourceName + " (" + strings.Join(expectedSources, "/") + ")",
+		fix:    "git -C " + repoPath + " remote set-url origin " + expectedUrl + ", or set [repo." + repoName + "].source",
+	}, false
+}
+
+// findStrayDirs 找出存储目录第一层中既不是存储库、也不包含存储库的目录
+//
+// 参数：
+//   - root: 存储目录
+//   - usedPaths: 存储库路径
+//
+// 返回：
+//   - 目录路径
+func findStrayDirs(root string, usedPaths []string) []string {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+
+	var strayDirs []string
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		dirPath := filepath.Join(root, entry.Name())
+		used := slices.ContainsFunc(usedPaths, func(usedPath string) bool {
+			return usedPath == dirPath || strings.HasPrefix(usedPath, dirPath+string(filepath.Separator))
+		})
+		if !used {
+			strayDirs = append(strayDirs, dirPath)
+		}
+	}
+	return strayDirs
+}
+
+// relativePath 获取相对于存储目录的路径，无法计算时返回原路径
+//
+// 参数：
+//   - root: 存储目录
+//   - path: 路径
+//
+// 返回：
+//   - 相对路径
+func relativePath(root, path string) string {
+	relPath, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return relPath
+}
+
+// printDoctorCheck 输出一项检查的结果
+//
+// 参数：
+//   - name: 检查项名称
+//   - issues: 发现的问题
+//
+// 返回：
+//   - 问题数
+func printDoctorCheck(name string, issues []doctorIssue) int {
+	actionPrint := color.Sprintf("%s Checking %s: ", general.RunFlag, general.FgCyanText(name))
+	if len(issues) == 0 {
+		color.Printf("%s%s %s\n", actionPrint, general.SuccessFlag, general.SecondaryText("pass"))
+		return 0
+	}
+
+	color.Printf("%s%s %s\n", actionPrint, general.WarningFlag, general.WarnText(len(issues), " found"))
+	length := len(general.RunFlag) + len("Checking") // 问题缩进长度
+	for index, issue := range issues {
+		joiner := func() string { // 检查项和问题的输出连接符
+			if index == len(issues)-1 {
+				return general.JoinerFinish
+			}
+			return general.JoinerIng
+		}()
+		color.Printf("%s%s %s: %s\n", strings.Repeat(" ", length), joiner, general.FgMagentaText(issue.item), general.SecondaryText(issue.detail))
+		if issue.fix != "" {
+			continuation := "│   " // 修复建议的缩进，与连接符对齐
+			if index == len(issues)-1 {
+				continuation = "    "
+			}
+			color.Printf("%s%s%s %s\n", strings.Repeat(" ", length), continuation, general.InfoText("Fix:"), issue.fix)
+		}
+	}
+	return len(issues)
+}
@@ -0,0 +1,157 @@
+/*
+File: drift.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 16:21:09
+
+Description: 子命令 'drift' 的实现
+*/
+
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/gookit/color"
+	"github.com/yhyj/curator/general"
+)
+
+// ReportDrift 对比主存储库源和镜像源上每个存储库的分支和标签，报告缺失或指向不同提交的引用
+//
+// 参数：
+//   - config: 解析 toml 配置文件得到的配置项
+//   - source: 主存储库源名称
+func ReportDrift(config *general.Config, source string) {
+	sources := general.GetSources(config)
+	if _, ok := sources[source]; !ok {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Source %s is not configured", source))
+		return
+	}
+	mirrorSources := general.GetMirrorSourceNames(config, source)
+	if len(mirrorSources) == 0 {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Source %s has no mirror source", source))
+		return
+	}
+	compareSources := append([]string{source}, mirrorSources...)
+
+	// 获取公钥
+	publicKeys, err := general.GetSSHAuth(config)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 只处理使用主存储库源的存储库
+	var repoNames []string
+	for _, repoName := range config.Git.Repos {
+		if belongsToSource(config, repoName, source) {
+			repoNames = append(repoNames, repoName)
+		}
+	}
+	sort.Strings(repoNames)
+
+	// 输出基础信息
+	color.Printf("%s Compare repository on %s: %d repositories\n", general.InfoText("INFO:"), general.FgGreenText(strings.Join(compareSources, ", ")), len(repoNames))
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+
+	driftedNum := 0
+	for _, repoName := range repoNames {
+		actionPrint := color.Sprintf("%s Checking %s: ", general.RunFlag, general.FgCyanText(repoName))
+		general.WaitSpinner.Prefix = actionPrint
+		general.WaitSpinner.Start()
+
+		// 获取每个存储库源的引用
+		sourceRefs := make(map[string]map[plumbing.ReferenceName]string)
+		var errList []string
+		for _, compareSource := range compareSources {
+			refs, err := general.ListRemoteRefs(sources[compareSource].Url, sources[compareSource].Username, general.RepoBaseName(repoName), publicKeys)
+			if err != nil {
+				errList = append(errList, compareSource+": "+err.Error())
+				continue
+			}
+			sourceRefs[compareSource] = refs
+		}
+		general.WaitSpinner.Stop()
+
+		if len(errList) > 0 {
+			color.Printf("%s%s %s\n", actionPrint, general.ErrorFlag, general.DangerText(strings.Join(errList, "; ")))
+			driftedNum++
+			continue
+		}
+
+		// 对比引用
+		drifts := general.CompareRemoteRefs(sourceRefs)
+		if len(drifts) == 0 {
+			color.Printf("%s%s %s\n", actionPrint, general.FgBlueText(general.LatestFlag), general.SecondaryText("In sync"))
+			continue
+		}
+		driftedNum++
+		color.Printf("%s%s %s\n", actionPrint, general.WarningFlag, general.WarnText(len(drifts), " refs drifted"))
+		length := len(general.RunFlag) + len("Checking") // 引用缩进长度
+		for index, drift := range drifts {
+			joiner := func() string { // 存储库和引用的输出连接符
+				if index == len(drifts)-1 {
+					return general.JoinerFinish
+				}
+				return general.JoinerIng
+			}()
+			color.Printf("%s%s %s: %s\n", strings.Repeat(" ", length), joiner, general.FgMagentaText(refDisplayName(drift.Ref)), describeDrift(drift, compareSources))
+		}
+
+		// 添加一个延时，使输出更加顺畅
+		general.Delay(general.DelayTime)
+	}
+
+	color.Printf("%s %d/%d repositories drifted\n", general.InfoText("INFO:"), driftedNum, len(repoNames))
+}
+
+// refDisplayName 获取引用的显示名称
+//
+// 参数：
+//   - ref: 引用名
+//
+// 返回：
+//   - 显示名称，例如 'branch main'、'tag v1.0.0'
+func refDisplayName(ref plumbing.ReferenceName) string {
+	if ref.IsTag() {
+		return "tag " + ref.Short()
+	}
+	return "branch " + ref.Short()
+}
+
+// describeDrift 描述引用在各存储库源之间的差异
+//
+// 参数：
+//   - drift: 引用差异
+//   - sources: 参与对比的存储库源名称
+//
+// 返回：
+//   - 差异描述
+func describeDrift(drift general.RefDrift, sources []string) string {
+	var missing, hashes []string
+	for _, source := range sources {
+		hash, ok := drift.Hashes[source]
+		if !ok {
+			missing = append(missing, source)
+			continue
+		}
+		hashes = append(hashes, source+" "+hash[:7])
+	}
+
+	var parts []string
+	if drift.Diverged() {
+		parts = append(parts, general.DangerText("different commits (", strings.Join(hashes, ", "), ")"))
+	} else {
+		parts = append(parts, general.SecondaryText(strings.Join(hashes, ", ")))
+	}
+	if len(missing) > 0 {
+		parts = append(parts, general.WarnText("missing on ", strings.Join(missing, ", ")))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,232 @@
+/*
+File: exec.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 01:31:46
+
+Description: 子命令 'exec' 的实现
+*/
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/curator/general"
+)
+
+// ExecOptions exec 子命令的选项
+type ExecOptions struct {
+	Source   string // 只选择属于该存储库源的存储库
+	Group    string // 只选择属于该分组的存储库
+	All      bool   // 选择所有已克隆的存储库，不显示选择器
+	Jobs     int    // 并行运行的命令数
+	FailFast bool   // 有命令失败后不再启动新的命令
+	Prefix   bool   // 在每行输出前加上存储库名，而不是按存储库分组输出
+}
+
+// execResult 在一个存储库中运行命令的结果
+type execResult struct {
+	repoName string // 存储库名
+	exitCode int    // 退出码，命令未运行时为 -1
+	result   string // 结果：ok、failed、skipped 或 not cloned
+}
+
+// ExecRepos 在所选存储库的目录中运行命令
+//
+//   - 指定了 Source、Group 或 All 时按条件选择存储库，否则由用户在选择器中选择
+//   - 命令的输出在命令结束后按存储库分组（或加上存储库名前缀）输出，最后输出每个存储库的退出码
+//
+// 参数：
+//   - config: 配置项
+//   - command: 命令及其参数
+//   - options: 选项
+//
+// 返回：
+//   - 所有命令都成功返回 true，否则返回 false
+func ExecRepos(config *general.Config, command []string, options ExecOptions) bool {
+	// 已克隆的存储库
+	sort.Strings(config.Git.Repos)
+	clonedRepos := make([]string, 0)
+	for _, repoName := range config.Git.Repos {
+		if isRepo, _, _ := general.IsLocalRepo(general.GetRepoPath(config, repoName)); isRepo {
+			clonedRepos = append(clonedRepos, repoName)
+		}
+	}
+
+	// 选择存储库
+	var selectedRepos []string
+	if options.Source != "" || options.Group != "" || options.All {
+		for _, repoName := range config.Git.Repos {
+			if options.Source != "" && !belongsToSource(config, repoName, options.Source) {
+				continue
+			}
+			if options.Group != "" && config.Repo[repoName].Group != options.Group {
+				continue
+			}
+			selectedRepos = append(selectedRepos, repoName)
+		}
+	} else {
+		negatives := strings.Builder{}
+		negatives.WriteString(color.Sprintf("%s Run %s in repositories: %d/%d cloned\n", general.InfoText("INFO:"), general.FgGreenText(strings.Join(command, " ")), len(clonedRepos), len(config.Git.Repos)))
+		negatives.WriteString(color.Sprintf("%s Repository root: %s (%s)\n", general.InfoText("INFO:"), general.PrimaryText(config.Storage.Path), general.FgGreenText(storageMode(config))))
+		var err error
+		selectedRepos, err = general.MultipleSelectionFilter(config.Git.Repos, clonedRepos, negatives.String())
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return false
+		}
+		sort.Strings(selectedRepos)
+	}
+	if len(selectedRepos) == 0 {
+		return true
+	}
+
+	jobs := max(options.Jobs, 1) // 并行运行的命令数
+	color.Printf("%s Run %s in %d repositories with %d jobs\n", general.InfoText("INFO:"), general.FgGreenText(strings.Join(command, " ")), len(selectedRepos), jobs)
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+
+	var (
+		outputMutex sync.Mutex // 保证每个存储库的输出不被打断
+		stopMutex   sync.Mutex // 保护 stopped
+		stopped     bool       // 是否因为 FailFast 停止启动新的命令
+		waitGroup   sync.WaitGroup
+	)
+	results := make([]execResult, len(selectedRepos))
+	indexes := make(chan int)
+	for job := 0; job < jobs; job++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for index := range indexes {
+				repoName := selectedRepos[index]
+				results[index] = execResult{repoName: repoName, exitCode: -1}
+
+				stopMutex.Lock()
+				skip := stopped
+				stopMutex.Unlock()
+				if skip {
+					results[index].result = "skipped"
+					continue
+				}
+
+				repoPath := general.GetRepoPath(config, repoName)
+				if isRepo, _, _ := general.IsLocalRepo(repoPath); !isRepo {
+					results[index].result = "not cloned"
+					continue
+				}
+
+				stdout, stderr, err := general.RunCommandToBufferInDir(repoPath, command[0], command[1:])
+				results[index].result = "ok"
+				if err == nil {
+					results[index].exitCode = 0
+				} else {
+					results[index].result = "failed"
+					var exitErr *exec.ExitError
+					if errors.As(err, &exitErr) {
+						results[index].exitCode = exitErr.ExitCode()
+					} else if stderr == "" {
+						stderr = err.Error() // 命令无法启动
+					}
+					if options.FailFast {
+						stopMutex.Lock()
+						stopped = true
+						stopMutex.Unlock()
+					}
+				}
+
+				outputMutex.Lock()
+				printExecOutput(results[index], stdout, stderr, options.Prefix)
+				outputMutex.Unlock()
+			}
+		}()
+	}
+	for index := range selectedRepos {
+		indexes <- index
+	}
+	close(indexes)
+	waitGroup.Wait()
+
+	return printExecResults(results)
+}
+
+// printExecOutput 输出在一个存储库中运行命令的结果和输出
+//
+// 参数：
+//   - result: 运行结果
+//   - stdout: 命令的标准输出
+//   - stderr: 命令的标准错误
+//   - prefix: 是否在每行输出前加上存储库名
+func printExecOutput(result execResult, stdout, stderr string, prefix bool) {
+	var lines []string
+	for _, output := range []string{stdout, stderr} {
+		if output != "" {
+			lines = append(lines, strings.Split(output, "\n")...)
+		}
+	}
+
+	if prefix {
+		for _, line := range lines {
+			color.Printf("%s %s\n", general.FgCyanText("[", result.repoName, "]"), line)
+		}
+		return
+	}
+
+	status := color.Sprintf("%s", general.SuccessFlag)
+	if result.exitCode > 0 {
+		status = color.Sprintf("%s %s", general.ErrorFlag, general.DangerText("exit ", result.exitCode))
+	} else if result.result != "ok" {
+		status = color.Sprintf("%s %s", general.ErrorFlag, general.DangerText(result.result))
+	}
+	color.Printf("%s Running in %s: %s\n", general.RunFlag, general.FgCyanText(result.repoName), status)
+	indent := strings.Repeat(" ", len(general.RunFlag)+len("Running"))
+	for index, line := range lines {
+		joiner := general.JoinerIng
+		if index == len(lines)-1 {
+			joiner = general.JoinerFinish
+		}
+		color.Printf("%s%s %s\n", indent, joiner, line)
+	}
+}
+
+// printExecResults 以表格形式输出每个存储库的运行结果
+//
+// 参数：
+//   - results: 运行结果
+//
+// 返回：
+//   - 所有命令都成功返回 true，否则返回 false
+func printExecResults(results []execResult) bool {
+	repoWidth := len("Repository")
+	for _, result := range results {
+		repoWidth = max(repoWidth, len(result.repoName))
+	}
+
+	succeeded := true
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+	color.Println(general.InfoText(fmt.Sprintf("%-*s  %4s  %s", repoWidth, "Repository", "Exit", "Result")))
+	color.Println(strings.Repeat(general.Separator2st, general.SeparatorBaseLength))
+	for _, result := range results {
+		resultText := general.SuccessText
+		switch result.result {
+		case "failed", "not cloned":
+			resultText = general.DangerText
+			succeeded = false
+		case "skipped":
+			resultText = general.WarnText
+		}
+		exitCode := "-"
+		if result.exitCode >= 0 {
+			exitCode = fmt.Sprint(result.exitCode)
+		}
+		color.Printf("%s  %4s  %s\n", general.FgCyanText(fmt.Sprintf("%-*s", repoWidth, result.repoName)), exitCode, resultText(result.result))
+	}
+	return succeeded
+}
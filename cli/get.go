@@ -0,0 +1,143 @@
+/*
+File: get.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 18:31:26
+
+Description: 子命令 'get' 的实现
+*/
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/curator/general"
+)
+
+// GetRepo Clone 指定的存储库并将其添加到配置文件
+//
+//   - 支持 SSH 地址、HTTPS 地址和 '<用户名>/<存储库名>' 简写，简写使用 source 指定的存储库源地址
+//   - 地址和用户名与已有存储库源都不匹配时，新增名为 '<主机地址>-<用户名>' 的存储库源
+//   - 已有来自其他存储库源或用户的同名存储库时，以 '<用户名>/<存储库名>'（仍冲突时为 '<主机地址>:<用户名>/<存储库名>'）作为存储库键
+//   - 以文本方式修改配置文件，保留原有格式和注释
+//
+// 参数：
+//   - configFile: 配置文件路径
+//   - target: 存储库地址或简写
+//   - source: 简写使用的存储库源名称
+func GetRepo(configFile, target, source string) {
+	config, err := loadConfig(configFile)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 解析存储库地址
+	url, username, repoName, err := parseGetTarget(config, target, source)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 确定存储库键，已有来自其他存储库源或用户的同名存储库时使用包含用户名的键
+	repoKey, registered := general.FindRepoKey(config, url, username, repoName)
+
+	// 匹配存储库源
+	sourceName := general.FindSourceName(config, url, username)
+	newSource := sourceName == ""
+	if newSource {
+		sourceName = general.NewSourceName(url, username)
+		if config.Source == nil {
+			config.Source = make(map[string]general.SourceConfig)
+		}
+		config.Source[sourceName] = general.SourceConfig{Url: url, Username: username}
+	}
+
+	// 更新内存中的配置项，使 clone 能获取存储库路径
+	if !registered {
+		config.Git.Repos = append(config.Git.Repos, repoKey)
+		if sourceName != "github" && sourceName != "gitea" {
+			if config.Repo == nil {
+				config.Repo = make(map[string]general.RepoConfig)
+			}
+			repoConfig := config.Repo[repoKey]
+			repoConfig.Source = sourceName
+			config.Repo[repoKey] = repoConfig
+		}
+	}
+
+	// 输出基础信息
+	color.Printf("%s Get repository %s from %s\n", general.InfoText("INFO:"), general.FgCyanText(repoKey), general.FgGreenText(sourceName))
+	color.Printf("%s Repository root: %s (%s)\n", general.InfoText("INFO:"), general.PrimaryText(config.Storage.Path), general.FgGreenText(storageMode(config)))
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+
+	// Clone 并执行脚本队列，失败时不修改配置文件
+	repoPath := general.GetRepoPath(config, repoKey)
+	clone(config, getRepoSource(config, sourceName), repoPath, repoKey, config.Script.RunQueue)
+	if isRepo, _, _ := general.IsLocalRepo(repoPath); !isRepo {
+		return
+	}
+
+	// 更新配置文件
+	if registered {
+		color.Printf("Update %s: %s\n", general.PrimaryText(configFile), general.SecondaryText(repoKey, " is already in the configuration file"))
+		return
+	}
+	if newSource {
+		if err := general.AppendTableToConfigFile(configFile, []string{"source", sourceName}, map[string]string{"url": url, "username": username}); err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+	}
+	if err := general.AppendRepoToConfigFile(configFile, repoKey); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	if repoConfig, ok := config.Repo[repoKey]; ok && repoConfig.Source != "" {
+		if err := general.AppendTableToConfigFile(configFile, []string{"repo", repoKey}, map[string]string{"source": repoConfig.Source}); err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+	}
+	color.Printf("Update %s: %s\n", general.PrimaryText(configFile), general.SuccessText(repoKey, " added"))
+}
+
+// parseGetTarget 解析存储库地址或简写
+//
+// 参数：
+//   - config: 配置项
+//   - target: 存储库地址或 '<用户名>/<存储库名>' 简写
+//   - source: 简写使用的存储库源名称
+//
+// 返回：
+//   - 存储库源地址
+//   - 用户名
+//   - 存储库名
+//   - 错误信息
+func parseGetTarget(config *general.Config, target, source string) (string, string, string, error) {
+	url, path, err := general.ParseRepoUrl(target)
+	if err != nil {
+		repoSource, ok := general.GetSources(config)[source]
+		if !ok {
+			return "", "", "", fmt.Errorf("Source %s is not configured", source)
+		}
+		url, path = repoSource.Url, strings.TrimSuffix(strings.Trim(target, "/"), ".git")
+		if !strings.Contains(path, "/") { // 只有存储库名时使用存储库源的用户名
+			path = repoSource.Username + "/" + path
+		}
+	}
+
+	slashIndex := strings.LastIndex(path, "/")
+	if slashIndex <= 0 || slashIndex == len(path)-1 {
+		return "", "", "", fmt.Errorf("Unable to determine the owner and name of %s", target)
+	}
+	return url, path[:slashIndex], path[slashIndex+1:], nil
+}
@@ -0,0 +1,362 @@
+/*
+File: lock.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 10:36:27
+
+Description: 子命令 'lock' 和 'sync' 的实现
+*/
+
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/gookit/color"
+	"github.com/yhyj/curator/general"
+)
+
+// CreateLockFile 记录所有已 Clone 存储库及其子模块当前所在的 Commit，写入锁文件
+//
+// 参数：
+//   - config: 解析 toml 配置文件得到的配置项
+//   - lockFile: 锁文件路径
+func CreateLockFile(config *general.Config, lockFile string) {
+	// 显示项排序
+	sort.Strings(config.Git.Repos)
+
+	// 输出基础信息
+	color.Printf("%s Lock repositories: %d configured\n", general.InfoText("INFO:"), len(config.Git.Repos))
+	color.Printf("%s Repository root: %s\n", general.InfoText("INFO:"), general.PrimaryText(config.Storage.Path))
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+
+	lock := general.Lock{}
+	for _, repoName := range config.Git.Repos {
+		actionPrint := color.Sprintf("%s Locking %s: ", general.RunFlag, general.FgCyanText(repoName))
+
+		repoPath := general.GetRepoPath(config, repoName)
+		isRepo, repo, headRef := general.IsLocalRepo(repoPath)
+		if !isRepo {
+			color.Printf("%s%s %s\n", actionPrint, general.WarningFlag, general.WarnText("The local repository does not exist"))
+			continue
+		}
+		if headRef == nil {
+			color.Printf("%s%s %s\n", actionPrint, general.WarningFlag, general.WarnText("HEAD does not point to any commit"))
+			continue
+		}
+
+		originUrl := general.GetRepoOriginUrl(repo)
+		lockedRepo := general.LockedRepo{
+			Name:   repoName,
+			Source: getSourceName(config, originUrl),
+			Url:    originUrl,
+			Branch: func() string {
+				if headRef.Name().IsBranch() {
+					return headRef.Name().Short()
+				}
+				return "" // HEAD 处于分离状态
+			}(),
+			Hash: headRef.Hash().String(),
+		}
+
+		// 记录子模块当前所在的 Commit，裸存储库没有工作树，跳过
+		worktree, err := repo.Worktree()
+		if err == nil {
+			submodules, err := general.GetLocalRepoSubmoduleInfo(worktree)
+			if err != nil {
+				fileName, lineNo := general.GetCallerInfo()
+				color.Printf("%s%s %s %s\n", actionPrint, general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+				continue
+			}
+			for _, submodule := range submodules {
+				status, err := submodule.Status()
+				if err != nil || status.Current.IsZero() {
+					continue // 子模块未初始化
+				}
+				lockedRepo.Submodules = append(lockedRepo.Submodules, general.LockedSubmodule{
+					Name: submodule.Config().Name,
+					Path: submodule.Config().Path,
+					Hash: status.Current.String(),
+				})
+			}
+		}
+
+		lock.Repos = append(lock.Repos, lockedRepo)
+		color.Printf("%s%s %s %s\n", actionPrint, general.SuccessFlag, general.FgGreenText(lockedRepo.Hash[:6]), general.SecondaryText("[", headRef.Name().Short(), "]"))
+		for index, lockedSubmodule := range lockedRepo.Submodules {
+			joiner := general.JoinerIng
+			if index == len(lockedRepo.Submodules)-1 {
+				joiner = general.JoinerFinish
+			}
+			color.Printf("%s%s %s %s: %s\n", strings.Repeat(" ", len(general.RunFlag)+len("Locking")), joiner, general.SubmoduleFlag, general.FgMagentaText(lockedSubmodule.Name), general.FgGreenText(lockedSubmodule.Hash[:6]))
+		}
+	}
+
+	if err := general.WriteLockFile(lockFile, &lock); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	color.Printf("Write %s: %s\n", general.PrimaryText(lockFile), general.SuccessText(len(lock.Repos), " repositories locked"))
+}
+
+// SyncLockedRepos 对照锁文件检查本地存储库，报告偏差
+//
+//   - locked 为 true 时 Clone 缺失的存储库，并将存储库及其子模块检出到锁文件记录的 Commit
+//
+// 参数：
+//   - config: 解析 toml 配置文件得到的配置项
+//   - lockFile: 锁文件路径
+//   - locked: 是否按锁文件修正偏差
+func SyncLockedRepos(config *general.Config, lockFile string, locked bool) {
+	lock, err := general.ReadLockFile(lockFile)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 获取公钥
+	publicKeys, err := general.GetSSHAuth(config)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 输出基础信息
+	color.Printf("%s Sync repositories with %s: %d locked\n", general.InfoText("INFO:"), general.PrimaryText(lockFile), len(lock.Repos))
+	color.Printf("%s Repository root: %s\n", general.InfoText("INFO:"), general.PrimaryText(config.Storage.Path))
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+
+	deviationNum := 0 // 存在偏差的存储库数
+	for _, lockedRepo := range lock.Repos {
+		repoPath := general.GetRepoPath(config, lockedRepo.Name)
+
+		// 缺失的存储库先 Clone
+		isRepo, repo, _ := general.IsLocalRepo(repoPath)
+		if !isRepo {
+			if !locked {
+				deviationNum++
+				color.Printf("%s Syncing %s: %s %s\n", general.RunFlag, general.FgCyanText(lockedRepo.Name), general.WarningFlag, general.WarnText("The local repository does not exist"))
+				continue
+			}
+			sourceName, err := getLockedSourceName(config, lockedRepo)
+			if err != nil {
+				deviationNum++
+				color.Printf("%s Syncing %s: %s %s\n", general.RunFlag, general.FgCyanText(lockedRepo.Name), general.ErrorFlag, general.DangerText(err))
+				continue
+			}
+			clone(config, getRepoSource(config, sourceName), repoPath, lockedRepo.Name, config.Script.RunQueue)
+			if isRepo, repo, _ = general.IsLocalRepo(repoPath); !isRepo {
+				continue // Clone 失败，错误信息已输出
+			}
+		}
+
+		if syncLockedRepo(repo, lockedRepo, locked, publicKeys) {
+			deviationNum++
+		}
+
+		// 添加一个延时，使输出更加顺畅
+		general.Delay(general.DelayTime)
+	}
+
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+	if locked {
+		color.Printf("%s %d/%d repositories deviated from the lock file and were checked out\n", general.InfoText("INFO:"), deviationNum, len(lock.Repos))
+	} else {
+		color.Printf("%s %d/%d repositories deviate from the lock file (use --locked to check out the locked commits)\n", general.InfoText("INFO:"), deviationNum, len(lock.Repos))
+	}
+}
+
+// syncLockedRepo 对照锁文件检查单个存储库及其子模块，按需检出
+//
+// 参数：
+//   - repo: 本地存储库对象
+//   - lockedRepo: 锁文件中该存储库的记录
+//   - locked: 是否按锁文件修正偏差
+//   - publicKeys: ssh 公钥
+//
+// 返回：
+//   - 是否存在偏差
+func syncLockedRepo(repo *git.Repository, lockedRepo general.LockedRepo, locked bool, publicKeys *ssh.PublicKeys) bool {
+	actionPrint := color.Sprintf("%s Syncing %s: ", general.RunFlag, general.FgCyanText(lockedRepo.Name))
+	deviated := false
+
+	// 主存储库
+	if deviation, err := syncLockedCommit(repo, lockedRepo.Branch, lockedRepo.Hash, locked, publicKeys); err != nil {
+		deviated = true
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s%s %s %s\n", actionPrint, general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+	} else if deviation != "" {
+		deviated = true
+		color.Printf("%s%s %s %s\n", actionPrint, general.WarningFlag, deviation, general.SecondaryText("[", lockedRepo.Branch, "]"))
+	} else {
+		color.Printf("%s%s %s %s\n", actionPrint, general.SuccessFlag, general.FgGreenText(lockedRepo.Hash[:6]), general.SecondaryText("[", lockedRepo.Branch, "]"))
+	}
+
+	// 子模块
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return deviated
+	}
+	length := len(general.RunFlag) + len("Syncing") // 子模块缩进长度
+	for index, lockedSubmodule := range lockedRepo.Submodules {
+		joiner := general.JoinerIng
+		if index == len(lockedRepo.Submodules)-1 {
+			joiner = general.JoinerFinish
+		}
+		subActionPrint := color.Sprintf("%s%s %s %s: ", strings.Repeat(" ", length), joiner, general.SubmoduleFlag, general.FgMagentaText(lockedSubmodule.Name))
+
+		submodule, err := worktree.Submodule(lockedSubmodule.Name)
+		if err != nil {
+			deviated = true
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s%s %s %s\n", subActionPrint, general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			continue
+		}
+		if locked {
+			if err := submodule.Update(&git.SubmoduleUpdateOptions{Init: true, Auth: publicKeys}); err != nil {
+				deviated = true
+				fileName, lineNo := general.GetCallerInfo()
+				color.Printf("%s%s %s %s\n", subActionPrint, general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+				continue
+			}
+		}
+		submoduleRepo, err := submodule.Repository()
+		if err != nil {
+			deviated = true
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s%s %s %s\n", subActionPrint, general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			continue
+		}
+
+		if deviation, err := syncLockedCommit(submoduleRepo, "", lockedSubmodule.Hash, locked, publicKeys); err != nil {
+			deviated = true
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s%s %s %s\n", subActionPrint, general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		} else if deviation != "" {
+			deviated = true
+			color.Printf("%s%s %s\n", subActionPrint, general.WarningFlag, deviation)
+		} else {
+			color.Printf("%s%s %s\n", subActionPrint, general.SuccessFlag, general.FgGreenText(lockedSubmodule.Hash[:6]))
+		}
+	}
+
+	return deviated
+}
+
+// syncLockedCommit 比较存储库 HEAD 与锁定的 Commit，按需检出
+//
+//   - 锁定的分支恰好指向锁定的 Commit 时检出该分支，否则以分离 HEAD 的方式检出锁定的 Commit
+//
+// 参数：
+//   - repo: 本地存储库对象
+//   - branch: 锁定的分支名，为空则直接检出 Commit
+//   - hash: 锁定的 Commit 的 Hash 值
+//   - locked: 是否按锁文件修正偏差
+//   - publicKeys: ssh 公钥
+//
+// 返回：
+//   - 偏差描述，无偏差时为空字符串
+//   - 错误信息
+func syncLockedCommit(repo *git.Repository, branch, hash string, locked bool, publicKeys *ssh.PublicKeys) (string, error) {
+	currentHash := "000000"
+	if headRef := general.GetRepoHeadRef(repo); headRef != nil {
+		currentHash = headRef.Hash().String()
+		if currentHash == hash {
+			return "", nil
+		}
+	}
+	deviation := color.Sprintf("%s %s %s", general.FgBlueText(currentHash[:6]), general.Indicator, general.FgGreenText(hash[:6]))
+
+	if !locked {
+		return deviation, nil
+	}
+	// 裸镜像存储库没有工作树，只报告偏差
+	if _, err := repo.Worktree(); err == git.ErrIsBareRepository {
+		return deviation, fmt.Errorf("Cannot check out the locked commit in a bare mirror repository, pull it instead")
+	}
+
+	// 锁定的分支指向锁定的 Commit 时切换到该分支
+	if branch != "" {
+		if branchRef, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true); err == nil && branchRef.Hash().String() == hash {
+			worktree, err := repo.Worktree()
+			if err != nil {
+				return deviation, err
+			}
+			return deviation, general.CheckoutBranch(worktree, branch)
+		}
+	}
+
+	return deviation, general.CheckoutCommit(repo, hash, publicKeys)
+}
+
+// getLockedSourceName 获取 Clone 锁文件中的存储库使用的存储库源
+//
+//   - 依次使用锁文件记录的存储库源、[repo.<存储库名>] 中的 source 和与记录的地址匹配的存储库源，都不可用时返回错误，不回退到 github
+//
+// 参数：
+//   - config: 配置项
+//   - lockedRepo: 锁文件中该存储库的记录
+//
+// 返回：
+//   - 存储库源名称
+//   - 错误信息
+func getLockedSourceName(config *general.Config, lockedRepo general.LockedRepo) (string, error) {
+	sources := general.GetSources(config)
+	for _, sourceName := range []string{lockedRepo.Source, config.Repo[lockedRepo.Name].Source} {
+		if _, ok := sources[sourceName]; ok && sourceName != "" {
+			return sourceName, nil
+		}
+	}
+	if sourceName := getSourceName(config, lockedRepo.Url); sourceName != "" {
+		return sourceName, nil
+	}
+	if lockedRepo.Source != "" {
+		return "", fmt.Errorf("Source %s is not configured and no configured source matches %s", lockedRepo.Source, lockedRepo.Url)
+	}
+	return "", fmt.Errorf("No configured source matches %s, set 'source' in [repo.%s]", lockedRepo.Url, lockedRepo.Name)
+}
+
+// getSourceName 根据存储库地址判断其所属的存储库源
+//
+//   - 地址的主机和所有者与存储库源的地址和用户名都相同才算匹配，github 和 gitea 优先
+//
+// 参数：
+//   - config: 配置项
+//   - url: 存储库地址
+//
+// 返回：
+//   - 存储库源名称，无法判断时为空字符串
+func getSourceName(config *general.Config, url string) string {
+	host, path, err := general.ParseRepoUrl(url)
+	if err != nil {
+		return ""
+	}
+	owner, _, _ := strings.Cut(path, "/")
+
+	sources := general.GetSources(config)
+	sourceNames := make([]string, 0, len(sources))
+	for name := range sources {
+		sourceNames = append(sourceNames, name)
+	}
+	sort.Slice(sourceNames, func(i, j int) bool {
+		iPreferred, jPreferred := sourceNames[i] == "github" || sourceNames[i] == "gitea", sourceNames[j] == "github" || sourceNames[j] == "gitea"
+		if iPreferred != jPreferred {
+			return iPreferred
+		}
+		return sourceNames[i] < sourceNames[j]
+	})
+	for _, name := range sourceNames {
+		if source := sources[name]; strings.EqualFold(source.Url, host) && strings.EqualFold(source.Username, owner) {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+/*
+File: lock_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 12:41:09
+
+Description: 测试锁文件中存储库的存储库源的确定
+*/
+
+package cli
+
+import (
+	"testing"
+
+	"github.com/yhyj/curator/general"
+)
+
+// TestGetLockedSourceName 锁文件没有记录存储库源时按 [repo.<name>] 或记录的地址确定，都不匹配时返回错误而不是回退到 github
+func TestGetLockedSourceName(t *testing.T) {
+	config := &general.Config{}
+	config.Git.GithubUrl, config.Git.GithubUsername = "github.com", "ops"
+	config.Source = map[string]general.SourceConfig{"work": {Url: "git.example.com", Username: "team"}}
+	config.Repo = map[string]general.RepoConfig{"pinned": {Source: "work"}}
+
+	tests := []struct {
+		lockedRepo general.LockedRepo
+		want       string
+	}{
+		{general.LockedRepo{Name: "tools", Source: "work", Url: "git@github.com:ops/tools.git"}, "work"},
+		{general.LockedRepo{Name: "pinned", Url: "git@elsewhere.example.com:x/pinned.git"}, "work"},
+		{general.LockedRepo{Name: "docs", Url: "git@git.example.com:team/docs.git"}, "work"},
+		{general.LockedRepo{Name: "tools", Url: "https://github.com/ops/tools.git"}, "github"},
+		{general.LockedRepo{Name: "gone", Source: "old", Url: "git@git.example.com:team/gone.git"}, "work"},
+	}
+	for _, test := range tests {
+		if got, err := getLockedSourceName(config, test.lockedRepo); err != nil || got != test.want {
+			t.Errorf("getLockedSourceName(%+v) = %q, %v, want %q", test.lockedRepo, got, err, test.want)
+		}
+	}
+
+	for _, lockedRepo := range []general.LockedRepo{
+		{Name: "other", Url: "git@gitlab.com:team/other.git"},
+		{Name: "other", Source: "old", Url: "git@gitlab.com:team/other.git"},
+	} {
+		if got, err := getLockedSourceName(config, lockedRepo); err == nil {
+			t.Errorf("getLockedSourceName(%+v) = %q, want an error", lockedRepo, got)
+		}
+	}
+}
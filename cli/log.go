@@ -0,0 +1,251 @@
+/*
+File: log.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 01:02:33
+
+Description: 子命令 'log' 的实现
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/gookit/color"
+	"github.com/yhyj/curator/general"
+)
+
+var (
+	TextFormat     = "text"     // 输出格式 - 终端文本
+	JsonFormat     = "json"     // 输出格式 - JSON
+	MarkdownFormat = "markdown" // 输出格式 - Markdown
+)
+
+// logEntry 跨存储库提交日志的一条记录
+type logEntry struct {
+	Repo    string    `json:"repo"`    // 存储库名，子模块为 '<存储库名>/<子模块名>'
+	Hash    string    `json:"hash"`    // 提交哈希
+	Author  string    `json:"author"`  // 作者名
+	Email   string    `json:"email"`   // 作者邮箱
+	Date    time.Time `json:"date"`    // 作者时间
+	Subject string    `json:"subject"` // 提交信息的第一行
+}
+
+// ShowLog 按时间顺序列出所有已克隆存储库中指定时间之后的提交，可用于生成站会报告
+//
+// 参数：
+//   - config: 配置项
+//   - since: 起始时间，参见 general.ParseSince
+//   - author: 作者名或邮箱（不区分大小写的子串匹配），'me' 表示各存储库 git 配置中的当前用户（精确匹配 user.email，未设置时精确匹配 user.name），为空时不过滤
+//   - submodules: 是否包括子模块
+//   - format: 输出格式，支持 'text'、'json' 和 'markdown'
+func ShowLog(config *general.Config, since, author string, submodules bool, format string) {
+	if format != TextFormat && format != JsonFormat && format != MarkdownFormat {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Unsupported format '%s', optional values are '%s', '%s' and '%s'", format, TextFormat, JsonFormat, MarkdownFormat))
+		return
+	}
+	sinceTime, err := general.ParseSince(since)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 收集各存储库的提交
+	var entries []logEntry
+	repoNames := append([]string(nil), config.Git.Repos...)
+	sort.Strings(repoNames)
+	for _, repoName := range repoNames {
+		repoPath := general.GetRepoPath(config, repoName)
+		if !general.FileExist(repoPath) {
+			continue
+		}
+		isRepo, repo, _ := general.IsLocalRepo(repoPath)
+		if !isRepo {
+			continue
+		}
+		repoEntries, err := collectLog(repo, repoName, sinceTime, author)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Fprintf(os.Stderr, "%s %s %s: %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), repoName, err)
+			continue
+		}
+		entries = append(entries, repoEntries...)
+
+		if !submodules {
+			continue
+		}
+		worktree, err := repo.Worktree()
+		if err != nil { // 裸镜像存储库没有子模块
+			continue
+		}
+		submoduleList, err := general.GetLocalRepoSubmoduleInfo(worktree)
+		if err != nil {
+			continue
+		}
+		for _, submodule := range submoduleList {
+			submoduleName := repoName + "/" + submodule.Config().Name
+			submoduleRepo, err := submodule.Repository()
+			if err != nil {
+				continue // 未初始化的子模块
+			}
+			submoduleEntries, err := collectLog(submoduleRepo, submoduleName, sinceTime, author)
+			if err != nil {
+				fileName, lineNo := general.GetCallerInfo()
+				color.Fprintf(os.Stderr, "%s %s %s: %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), submoduleName, err)
+				continue
+			}
+			entries = append(entries, submoduleEntries...)
+		}
+	}
+
+	// 按时间从新到旧排列
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.After(entries[j].Date)
+	})
+
+	switch format {
+	case JsonFormat:
+		if entries == nil {
+			entries = []logEntry{}
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		fmt.Println(string(data))
+	case MarkdownFormat:
+		fmt.Print(markdownLog(entries, sinceTime))
+	default:
+		color.Printf("%s Commits since %s: %d\n", general.InfoText("INFO:"), general.PrimaryText(sinceTime.Format(time.DateTime)), len(entries))
+		color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+		for _, entry := range entries {
+			color.Printf("%s %s %s %s %s %s\n", general.SecondaryText(entry.Date.Format("2006-01-02 15:04")), general.FgCyanText(entry.Repo), general.FgBlueText(entry.Hash[:6]), general.FgMagentaText(entry.Author), entry.Subject, general.SecondaryText("(", general.RelativeTime(entry.Date), ")"))
+		}
+	}
+}
+
+// collectLog 获取存储库所有分支上指定时间之后的提交
+//
+// 参数：
+//   - repo: 存储库对象
+//   - repoName: 存储库名
+//   - since: 起始时间
+//   - author: 作者过滤条件，参见 ShowLog
+//
+// 返回：
+//   - 提交日志
+//   - 错误信息
+func collectLog(repo *git.Repository, repoName string, since time.Time, author string) ([]logEntry, error) {
+	// 'me' 指当前用户在该存储库中的身份，优先精确匹配邮箱，未设置邮箱时精确匹配作者名
+	var filter authorFilter
+	switch author {
+	case "":
+	case "me":
+		repoConfig, err := repo.ConfigScoped(gitconfig.GlobalScope)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case repoConfig.User.Email != "":
+			filter = authorFilter{email: strings.ToLower(repoConfig.User.Email)}
+		case repoConfig.User.Name != "":
+			filter = authorFilter{name: strings.ToLower(repoConfig.User.Name)}
+		default:
+			return nil, fmt.Errorf("user.name and user.email are not set in git config")
+		}
+	default:
+		filter = authorFilter{substring: strings.ToLower(author)}
+	}
+
+	commitIter, err := repo.Log(&git.LogOptions{All: true, Since: &since})
+	if err != nil {
+		return nil, err
+	}
+	defer commitIter.Close()
+
+	var entries []logEntry
+	err = commitIter.ForEach(func(commit *object.Commit) error {
+		if commit.Author.When.Before(since) {
+			return nil
+		}
+		if !filter.match(commit.Author) {
+			return nil
+		}
+		entries = append(entries, logEntry{
+			Repo:    repoName,
+			Hash:    commit.Hash.String(),
+			Author:  commit.Author.Name,
+			Email:   commit.Author.Email,
+			Date:    commit.Author.When,
+			Subject: general.CommitSubject(commit),
+		})
+		return nil
+	})
+	return entries, err
+}
+
+// authorFilter 提交作者过滤条件，所有字段均为小写，全部为空时不过滤
+type authorFilter struct {
+	email     string // 与作者邮箱精确匹配
+	name      string // 与作者名精确匹配
+	substring string // 作者名或邮箱包含该字符串
+}
+
+// match 检测提交作者是否匹配过滤条件
+//
+// 参数：
+//   - signature: 提交作者
+//
+// 返回：
+//   - 匹配返回 true，否则返回 false
+func (f authorFilter) match(signature object.Signature) bool {
+	name, email := strings.ToLower(signature.Name), strings.ToLower(signature.Email)
+	switch {
+	case f.email != "":
+		return email == f.email
+	case f.name != "":
+		return name == f.name
+	case f.substring != "":
+		return strings.Contains(name, f.substring) || strings.Contains(email, f.substring)
+	}
+	return true
+}
+
+// markdownLog 将提交日志转换为按日期分组的 Markdown
+//
+// 参数：
+//   - entries: 按时间从新到旧排列的提交日志
+//   - since: 起始时间
+//
+// 返回：
+//   - Markdown 文本
+func markdownLog(entries []logEntry, since time.Time) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("# Commits since %s\n", since.Format(time.DateTime)))
+	if len(entries) == 0 {
+		builder.WriteString("\nNo commits.\n")
+		return builder.String()
+	}
+	lastDate := ""
+	for _, entry := range entries {
+		if date := entry.Date.Local().Format(time.DateOnly); date != lastDate {
+			builder.WriteString(fmt.Sprintf("\n## %s\n\n", date))
+			lastDate = date
+		}
+		builder.WriteString(fmt.Sprintf("- **%s** `%s` %s (%s, %s)\n", entry.Repo, entry.Hash[:7], entry.Subject, entry.Author, entry.Date.Local().Format("15:04")))
+	}
+	return builder.String()
+}
@@ -0,0 +1,104 @@
+/*
+File: manifest.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 11:40:12
+
+Description: 子命令 'manifest' 的实现
+*/
+
+package cli
+
+import (
+	"os"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/curator/general"
+)
+
+// ImportManifestFile 将 repo 工具的 XML 清单导入配置文件
+//
+// 参数：
+//   - configFile: 配置文件路径
+//   - manifestFile: 清单文件路径
+//   - manifestUrl: 清单存储库的地址，用于解析相对的 fetch 地址
+func ImportManifestFile(configFile, manifestFile, manifestUrl string) {
+	// 读取配置文件
+	configTree, err := general.GetTomlConfig(configFile)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	config, err := general.LoadConfigToStruct(configTree)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 读取清单文件
+	manifest, err := general.ReadManifest(manifestFile)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 合并后写回配置文件
+	addedRepos, err := general.ImportManifest(config, manifest, manifestUrl)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	if err := general.SaveConfigChanges(configFile, config); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	color.Printf("%s Import %s: %d projects, %d new repositories\n", general.InfoText("INFO:"), general.PrimaryText(manifestFile), len(manifest.Projects), len(addedRepos))
+	if len(addedRepos) > 0 {
+		color.Printf("%s Added: %s\n", general.InfoText("INFO:"), general.FgCyanText(strings.Join(addedRepos, ", ")))
+	}
+	color.Printf("Update %s: %s\n", general.PrimaryText(configFile), general.SuccessText("file updated"))
+}
+
+// ExportManifestFile 将配置项导出为 repo 工具的 XML 清单
+//
+// 参数：
+//   - config: 解析 toml 配置文件得到的配置项
+//   - source: 作为默认 remote 的存储库源
+//   - output: 输出文件路径，为空时输出到标准输出
+func ExportManifestFile(config *general.Config, source, output string) {
+	manifest := general.ExportManifest(config, source)
+
+	if output == "" {
+		if err := general.WriteManifest(os.Stdout, manifest); err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		}
+		return
+	}
+
+	if err := general.CreateFile(output); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	file, err := os.OpenFile(output, os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	defer file.Close()
+	if err := general.WriteManifest(file, manifest); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	color.Printf("Export %s: %s\n", general.PrimaryText(output), general.SuccessText(len(manifest.Projects), " projects written"))
+}
@@ -0,0 +1,206 @@
+/*
+File: migrate.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 17:32:48
+
+Description: 子命令 'migrate' 的实现
+*/
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/gookit/color"
+	"github.com/yhyj/curator/general"
+)
+
+// MigrateRepo 将存储库从一个存储库源迁移到另一个存储库源，并更新本地存储库的 origin 和配置文件
+//
+//   - 目标是 Gitea 时使用 Gitea 的迁移 API，否则（或迁移 API 失败时）先在目标上创建存储库，再将源存储库的裸镜像推送过去
+//   - 存储库当前的存储库源必须是 from（没有单独指定存储库源的存储库属于 github 和 gitea），否则拒绝迁移
+//   - 迁移后配置文件中该存储库的 source 指向目标存储库源，'clone' 和 'pull' 将以目标为主存储库源
+//   - 存储布局包含 {source}、{host} 或 {owner} 时，本地存储库随之移动到新路径
+//
+// 参数：
+//   - configFile: 配置文件路径
+//   - repoName: 存储库名
+//   - from: 源存储库源名称
+//   - to: 目标存储库源名称
+func MigrateRepo(configFile, repoName, from, to string) {
+	config, err := loadConfig(configFile)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	if !slices.Contains(config.Git.Repos, repoName) {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Repository %s is not in the configuration file", repoName))
+		return
+	}
+
+	// 存储库当前必须属于源存储库源，否则会把另一个副本推送到目标并改写配置
+	if !belongsToSource(config, repoName, from) {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Repository %s uses source %s, not %s", repoName, general.GetRepoSourceName(config, repoName, general.DefaultSourceName(config)), from))
+		return
+	}
+
+	// 创建源和目标的存储库托管平台对象
+	sources := general.GetSources(config)
+	fromSource, fromOk := sources[from]
+	toSource, toOk := sources[to]
+	if !fromOk || !toOk {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Both %s and %s must be configured sources", from, to))
+		return
+	}
+	fromProvider, err := general.NewProvider(fromSource)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	defer fromProvider.Close()
+	toProvider, err := general.NewProvider(toSource)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	defer toProvider.Close()
+
+	// 获取公钥
+	publicKeys, err := general.GetSSHAuth(config)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 输出基础信息
+	color.Printf("%s Migrate repository %s from %s to %s\n", general.InfoText("INFO:"), general.FgCyanText(repoName), general.FgGreenText(from), general.FgGreenText(to))
+	color.Println(general.InfoText("INFO:"), general.SecondaryText("Storage mode:"), general.PrimaryText(storageMode(config)))
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+
+	// 迁移远端存储库
+	actionPrint := color.Sprintf("%s Migrating %s: ", general.RunFlag, general.FgCyanText(repoName))
+	general.WaitSpinner.Prefix = actionPrint
+	general.WaitSpinner.Start()
+	result, err := migrateRemoteRepo(fromProvider, toProvider, fromSource, toSource, general.RepoBaseName(repoName), publicKeys)
+	general.WaitSpinner.Stop()
+	if err != nil {
+		color.Printf("%s%s %s\n", actionPrint, general.ErrorFlag, general.DangerText(err))
+		return
+	}
+	color.Printf("%s%s %s\n", actionPrint, general.SuccessFlag, general.SecondaryText(result))
+
+	// 更新本地存储库的 origin
+	newUrl := general.BuildRepoUrl(toSource.Url, toSource.Username, general.RepoBaseName(repoName))
+	actionPrint = color.Sprintf("%s Updating %s: ", general.RunFlag, general.FgCyanText(repoName))
+	repoPath := general.GetRepoPath(config, repoName)
+	if isRepo, repo, _ := general.IsLocalRepo(repoPath); isRepo {
+		if err := general.SetRepoOriginUrl(repo, newUrl); err != nil {
+			color.Printf("%s%s %s\n", actionPrint, general.ErrorFlag, general.DangerText(err))
+		} else {
+			color.Printf("%s%s %s\n", actionPrint, general.SuccessFlag, general.SecondaryText("origin ", general.Indicator, " ", newUrl))
+		}
+	} else {
+		color.Printf("%s%s %s\n", actionPrint, general.WarningFlag, general.SecondaryText("Not a local repository, skip remote update"))
+	}
+
+	// 更新配置文件
+	if config.Repo == nil {
+		config.Repo = make(map[string]general.RepoConfig)
+	}
+	repoConfig := config.Repo[repoName]
+	repoConfig.Source = to
+	config.Repo[repoName] = repoConfig
+	if err := general.SetTableValueInConfigFile(configFile, []string{"repo", repoName}, "source", to); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	color.Printf("Update %s: %s\n", general.PrimaryText(configFile), general.SuccessText("[repo.", repoName, "] source = ", to))
+
+	// 存储布局包含存储库源时，将本地存储库移动到新路径
+	if newRepoPath := general.GetRepoPath(config, repoName); newRepoPath != repoPath && general.FileExist(repoPath) {
+		actionPrint = color.Sprintf("%s Relocating %s: ", general.RunFlag, general.FgCyanText(repoName))
+		if general.FileExist(newRepoPath) {
+			color.Printf("%s%s %s\n", actionPrint, general.WarningFlag, general.WarnText(newRepoPath, " already exists"))
+		} else if err := moveRepo(repoPath, newRepoPath, config.Storage.Path); err != nil {
+			color.Printf("%s%s %s\n", actionPrint, general.ErrorFlag, general.DangerText(err))
+		} else {
+			color.Printf("%s%s %s\n", actionPrint, general.SuccessFlag, general.SecondaryText(repoPath, " ", general.Indicator, " ", newRepoPath))
+		}
+	}
+}
+
+// migrateRemoteRepo 在目标存储库源上创建源存储库的副本
+//
+// 参数：
+//   - fromProvider: 源存储库托管平台对象
+//   - toProvider: 目标存储库托管平台对象
+//   - fromSource: 源存储库源配置
+//   - toSource: 目标存储库源配置
+//   - repoName: 存储库名
+//   - publicKeys: ssh 公钥
+//
+// 返回：
+//   - 迁移结果描述
+//   - 错误信息
+func migrateRemoteRepo(fromProvider, toProvider *general.Provider, fromSource, toSource general.SourceConfig, repoName string, publicKeys *ssh.PublicKeys) (string, error) {
+	// 目标上已存在则不再迁移
+	if _, err := toProvider.GetRepo(repoName); err == nil {
+		return fmt.Sprintf("Already exists on %s", toSource.Url), nil
+	} else if !general.IsNotFound(err) {
+		return "", err
+	}
+
+	fromRepo, err := fromProvider.GetRepo(repoName)
+	if err != nil {
+		return "", err
+	}
+
+	// 使用迁移 API
+	var migrateErr error
+	if toProvider.Type == "gitea" {
+		cloneAddr := "https://" + fromSource.Url + "/" + fromSource.Username + "/" + repoName + ".git"
+		if _, migrateErr = toProvider.MigrateRepo(cloneAddr, fromProvider.Type, fromProvider.Token, repoName, fromRepo.Description, fromRepo.Private); migrateErr == nil {
+			return "Migrated via " + toProvider.Type + " migration API", nil
+		}
+	}
+
+	// 创建存储库后推送裸镜像
+	if _, err := toProvider.CreateRepo(repoName, fromRepo.Description, fromRepo.Private); err != nil {
+		return "", err
+	}
+	mirrorPath, err := os.MkdirTemp("", "curator-migrate-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(mirrorPath)
+	mirrorRepo, err := general.MirrorRepoViaSSH(mirrorPath, fromSource.Url, fromSource.Username, repoName, publicKeys)
+	if err != nil {
+		return "", err
+	}
+	plans, err := general.PlanMirrorPush(mirrorRepo, nil, false)
+	if err != nil {
+		return "", err
+	}
+	if err := general.PushMirrorRefs(mirrorRepo, toSource.Url, toSource.Username, repoName, plans, publicKeys); err != nil && err != git.NoErrAlreadyUpToDate {
+		return "", err
+	}
+	result := fmt.Sprintf("Created on %s and pushed %d refs", toSource.Url, len(plans))
+	if migrateErr != nil {
+		result += fmt.Sprintf(" (migration API failed: %s)", migrateErr)
+	}
+	return result, nil
+}
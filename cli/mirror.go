@@ -0,0 +1,650 @@
+/*
+File: mirror.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 15:46:21
+
+Description: 子命令 'mirror' 的实现
+*/
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/gookit/color"
+	"github.com/yhyj/curator/general"
+)
+
+// EnsureMirrorRepos 检查主存储库源的存储库是否存在于每个镜像源，不存在则在镜像源上创建
+//
+//   - 新创建的存储库与主存储库源上的存储库保持相同的可见性和描述
+//
+// 参数：
+//   - config: 解析 toml 配置文件得到的配置项
+//   - source: 主存储库源名称
+//   - dryRun: 只报告需要创建的存储库，不实际创建
+func EnsureMirrorRepos(config *general.Config, source string, dryRun bool) {
+	// 创建主存储库源和镜像源的存储库托管平台对象
+	primaryProvider, mirrorProviders, err := getMirrorProviders(config, source)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	defer primaryProvider.Close()
+	for _, mirrorProvider := range mirrorProviders {
+		defer mirrorProvider.Close()
+	}
+	mirrorSources := general.GetMirrorSourceNames(config, source)
+
+	// 只处理使用主存储库源的存储库
+	var repoNames []string
+	for _, repoName := range config.Git.Repos {
+		if belongsToSource(config, repoName, source) {
+			repoNames = append(repoNames, repoName)
+		}
+	}
+	sort.Strings(repoNames)
+
+	// 输出基础信息
+	color.Printf("%s Ensure repository from %s on %s: %d repositories\n", general.InfoText("INFO:"), general.FgGreenText(source), general.FgGreenText(strings.Join(mirrorSources, ", ")), len(repoNames))
+	if dryRun {
+		color.Printf("%s Dry run, no repository will be created\n", general.InfoText("INFO:"))
+	}
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+
+	createdNum := 0
+	for _, repoName := range repoNames {
+		actionPrint := color.Sprintf("%s Ensuring %s: ", general.RunFlag, general.FgCyanText(repoName))
+		general.WaitSpinner.Prefix = actionPrint
+		general.WaitSpinner.Start()
+
+		// 获取主存储库源上的存储库信息
+		primaryRepo, err := primaryProvider.GetRepo(general.RepoBaseName(repoName))
+		if err != nil {
+			general.WaitSpinner.Stop()
+			color.Printf("%s", actionPrint)
+			if general.IsNotFound(err) {
+				color.Printf("%s %s\n", general.WarningFlag, general.SecondaryText("Not found on ", source))
+			} else {
+				fileName, lineNo := general.GetCallerInfo()
+				color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			}
+			continue
+		}
+
+		// 逐个检查镜像源
+		var results []string
+		for _, mirrorSource := range mirrorSources {
+			mirrorProvider := mirrorProviders[mirrorSource]
+			_, err := mirrorProvider.GetRepo(general.RepoBaseName(repoName))
+			switch {
+			case err == nil:
+				results = append(results, color.Sprintf("%s %s", general.FgBlueText(general.LatestFlag), general.SecondaryText(mirrorSource, " exists")))
+			case !general.IsNotFound(err):
+				results = append(results, color.Sprintf("%s %s", general.ErrorFlag, general.DangerText(mirrorSource, ": ", err)))
+			case dryRun:
+				results = append(results, color.Sprintf("%s %s", general.WarningFlag, general.WarnText(mirrorSource, " would be created")))
+			default:
+				if _, err := mirrorProvider.CreateRepo(general.RepoBaseName(repoName), primaryRepo.Description, primaryRepo.Private); err != nil {
+					results = append(results, color.Sprintf("%s %s", general.ErrorFlag, general.DangerText(mirrorSource, ": ", err)))
+				} else {
+					results = append(results, color.Sprintf("%s %s", general.SuccessFlag, general.SuccessText(mirrorSource, " created")))
+					createdNum++
+				}
+			}
+		}
+		general.WaitSpinner.Stop()
+		color.Printf("%s%s\n", actionPrint, strings.Join(results, "  "))
+
+		// 添加一个延时，使输出更加顺畅
+		general.Delay(general.DelayTime)
+	}
+
+	if !dryRun {
+		color.Printf("%s %d mirror repositories created\n", general.InfoText("INFO:"), createdNum)
+	}
+}
+
+// getMirrorProviders 创建主存储库源和所有镜像源的存储库托管平台对象
+//
+// 参数：
+//   - config: 配置项
+//   - source: 主存储库源名称
+//
+// 返回：
+//   - 主存储库源的存储库托管平台对象
+//   - 镜像源名称到存储库托管平台对象的映射
+//   - 错误信息
+func getMirrorProviders(config *general.Config, source string) (*general.Provider, map[string]*general.Provider, error) {
+	sources := general.GetSources(config)
+	primarySource, ok := sources[source]
+	if !ok {
+		return nil, nil, fmt.Errorf("Source %s is not configured", source)
+	}
+	primaryProvider, err := general.NewProvider(primarySource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mirrorSources := general.GetMirrorSourceNames(config, source)
+	if len(mirrorSources) == 0 {
+		primaryProvider.Close()
+		return nil, nil, fmt.Errorf("Source %s has no mirror source", source)
+	}
+	mirrorProviders := make(map[string]*general.Provider)
+	for _, mirrorSource := range mirrorSources {
+		mirrorProvider, err := general.NewProvider(sources[mirrorSource])
+		if err != nil {
+			primaryProvider.Close()
+			for _, mirrorProvider := range mirrorProviders {
+				mirrorProvider.Close()
+			}
+			return nil, nil, err
+		}
+		mirrorProviders[mirrorSource] = mirrorProvider
+	}
+
+	return primaryProvider, mirrorProviders, nil
+}
+
+// mirrorPushResult 一个存储库推送到一个镜像源的结果
+type mirrorPushResult struct {
+	repoName     string // 存储库名
+	mirrorSource string // 镜像源名称
+	pushed       int    // 已推送（或将推送）的引用数
+	rejected     int    // 被拒绝的引用数
+	result       string // 结果描述
+}
+
+// PushMirrors 将每个本地存储库的所有分支和标签推送到镜像源
+//
+//   - 推送前先从 origin 拉取，推送 origin 的分支和标签而不是可能已经落后的本地分支
+//   - 非快进更新默认被拒绝，指定 force 后才强制推送
+//
+// 参数：
+//   - config: 解析 toml 配置文件得到的配置项
+//   - source: 主存储库源名称
+//   - force: 是否允许非快进更新
+//   - dryRun: 只报告推送计划，不实际推送
+func PushMirrors(config *general.Config, source string, force, dryRun bool) {
+	sources := general.GetSources(config)
+	mirrorSources := general.GetMirrorSourceNames(config, source)
+	if len(mirrorSources) == 0 {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Source %s has no mirror source", source))
+		return
+	}
+
+	// 获取公钥
+	publicKeys, err := general.GetSSHAuth(config)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 只处理使用主存储库源的存储库
+	var repoNames []string
+	for _, repoName := range config.Git.Repos {
+		if belongsToSource(config, repoName, source) {
+			repoNames = append(repoNames, repoName)
+		}
+	}
+	sort.Strings(repoNames)
+
+	// 输出基础信息
+	color.Printf("%s Push repository to %s: %d repositories\n", general.InfoText("INFO:"), general.FgGreenText(strings.Join(mirrorSources, ", ")), len(repoNames))
+	if dryRun {
+		color.Printf("%s Dry run, nothing will be pushed\n", general.InfoText("INFO:"))
+	}
+	if force {
+		color.Printf("%s Non-fast-forward updates will be forced\n", general.InfoText("INFO:"))
+	}
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+
+	var results []mirrorPushResult
+	for _, repoName := range repoNames {
+		repoPath := general.GetRepoPath(config, repoName)
+		isRepo, repo, _ := general.IsLocalRepo(repoPath)
+		if !isRepo {
+			color.Printf("%s Pushing %s: %s %s\n", general.RunFlag, general.FgCyanText(repoName), general.WarningFlag, general.SecondaryText("Not a local repository"))
+			results = append(results, mirrorPushResult{repoName: repoName, result: "not cloned"})
+			continue
+		}
+
+		// 推送 origin 的最新引用，本地分支可能已经落后
+		general.WaitSpinner.Prefix = color.Sprintf("%s Fetching %s: ", general.RunFlag, general.FgCyanText(repoName))
+		general.WaitSpinner.Start()
+		err := general.FetchOriginRefs(repo, publicKeys)
+		general.WaitSpinner.Stop()
+		if err != nil {
+			color.Printf("%s Fetching %s: %s %s\n", general.RunFlag, general.FgCyanText(repoName), general.ErrorFlag, general.DangerText(err))
+			results = append(results, mirrorPushResult{repoName: repoName, result: "fetch failed"})
+			continue
+		}
+
+		for _, mirrorSource := range mirrorSources {
+			actionPrint := color.Sprintf("%s Pushing %s to %s: ", general.RunFlag, general.FgCyanText(repoName), general.FgGreenText(mirrorSource))
+			general.WaitSpinner.Prefix = actionPrint
+			general.WaitSpinner.Start()
+
+			result := mirrorPushResult{repoName: repoName, mirrorSource: mirrorSource}
+			plans, err := pushMirror(repo, sources[mirrorSource], general.RepoBaseName(repoName), publicKeys, force, dryRun)
+			general.WaitSpinner.Stop()
+			for _, plan := range plans {
+				switch plan.Status {
+				case general.PushNew, general.PushFastForward, general.PushForced:
+					result.pushed++
+				case general.PushRejected:
+					result.rejected++
+				}
+			}
+
+			switch {
+			case err != nil && err != git.NoErrAlreadyUpToDate:
+				result.result = "failed"
+				color.Printf("%s%s %s\n", actionPrint, general.ErrorFlag, general.DangerText(err))
+			case result.pushed == 0 && result.rejected == 0:
+				result.result = "up-to-date"
+				color.Printf("%s%s %s\n", actionPrint, general.FgBlueText(general.LatestFlag), general.SecondaryText("Already up-to-date"))
+			default:
+				result.result = "pushed"
+				if dryRun {
+					result.result = "to push"
+				}
+				flag := general.SuccessFlag
+				if result.rejected > 0 {
+					flag = general.WarningFlag
+				}
+				color.Printf("%s%s %s\n", actionPrint, flag, general.SecondaryText(result.pushed, " refs ", result.result, ", ", result.rejected, " rejected"))
+			}
+			results = append(results, result)
+
+			// 输出有变化的引用
+			var changedPlans []general.RefPush
+			for _, plan := range plans {
+				if plan.Status != general.PushUpToDate {
+					changedPlans = append(changedPlans, plan)
+				}
+			}
+			length := len(general.RunFlag) + len("Pushing") // 引用缩进长度
+			for index, plan := range changedPlans {
+				joiner := func() string { // 存储库和引用的输出连接符
+					if index == len(changedPlans)-1 {
+						return general.JoinerFinish
+					}
+					return general.JoinerIng
+				}()
+				color.Printf("%s%s %s: %s\n", strings.Repeat(" ", length), joiner, general.FgMagentaText(refDisplayName(plan.Ref)), describePush(plan))
+			}
+
+			// 添加一个延时，使输出更加顺畅
+			general.Delay(general.DelayTime)
+		}
+	}
+
+	// 输出结果表
+	printPushResults(results)
+}
+
+// pushMirror 制定推送计划并推送到一个镜像源
+//
+// 参数：
+//   - repo: 本地存储库对象
+//   - mirrorSource: 镜像源配置
+//   - repoName: 存储库名
+//   - publicKeys: ssh 公钥
+//   - force: 是否允许非快进更新
+//   - dryRun: 只制定推送计划，不实际推送
+//
+// 返回：
+//   - 推送计划
+//   - 错误信息
+func pushMirror(repo *git.Repository, mirrorSource general.SourceConfig, repoName string, publicKeys *ssh.PublicKeys, force, dryRun bool) ([]general.RefPush, error) {
+	remoteRefs, err := general.ListRemoteRefs(mirrorSource.Url, mirrorSource.Username, repoName, publicKeys)
+	if err != nil {
+		return nil, err
+	}
+	plans, err := general.PlanMirrorPush(repo, remoteRefs, force)
+	if err != nil || dryRun {
+		return plans, err
+	}
+	return plans, general.PushMirrorRefs(repo, mirrorSource.Url, mirrorSource.Username, repoName, plans, publicKeys)
+}
+
+// describePush 描述引用的推送计划
+//
+// 参数：
+//   - plan: 推送计划
+//
+// 返回：
+//   - 推送描述
+func describePush(plan general.RefPush) string {
+	switch plan.Status {
+	case general.PushNew:
+		return general.SuccessText(plan.Status, " ", plan.Local[:7])
+	case general.PushRejected:
+		return general.DangerText(plan.Status, " ", plan.Remote[:7], "...", plan.Local[:7], " (use --force to overwrite)")
+	case general.PushForced:
+		return general.WarnText(plan.Status, " ", plan.Remote[:7], "...", plan.Local[:7])
+	default:
+		return general.SuccessText(plan.Status, " ", plan.Remote[:7], "..", plan.Local[:7])
+	}
+}
+
+// printPushResults 以表格形式输出推送结果
+//
+// 参数：
+//   - results: 推送结果
+func printPushResults(results []mirrorPushResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	// 计算列宽
+	repoWidth, mirrorWidth := len("Repository"), len("Mirror")
+	for _, result := range results {
+		repoWidth = max(repoWidth, len(result.repoName))
+		mirrorWidth = max(mirrorWidth, len(result.mirrorSource))
+	}
+
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+	color.Println(general.InfoText(fmt.Sprintf("%-*s  %-*s  %6s  %8s  %s", repoWidth, "Repository", mirrorWidth, "Mirror", "Pushed", "Rejected", "Result")))
+	color.Println(strings.Repeat(general.Separator2st, general.SeparatorBaseLength))
+	for _, result := range results {
+		resultText := general.SuccessText
+		switch {
+		case result.result == "failed":
+			resultText = general.DangerText
+		case result.rejected > 0 || result.result == "not cloned":
+			resultText = general.WarnText
+		}
+		color.Printf("%s  %s  %6d  %8d  %s\n", general.FgCyanText(fmt.Sprintf("%-*s", repoWidth, result.repoName)), general.FgGreenText(fmt.Sprintf("%-*s", mirrorWidth, result.mirrorSource)), result.pushed, result.rejected, resultText(result.result))
+	}
+}
+
+// SetupServerMirrors 在 Gitea 上为每个存储库创建或更新推送到目标存储库源的推送镜像，并列出最近的同步状态
+//
+//   - 推送镜像使用目标存储库源的 HTTPS 地址和访问令牌
+//   - 已存在推送到同一地址的推送镜像时，同步间隔、推送时同步或访问令牌不同则创建新的推送镜像后删除旧的，否则保持不变
+//   - 访问令牌的指纹记录在推送镜像凭据指纹文件中，用于判断访问令牌是否变化
+//
+// 参数：
+//   - config: 解析 toml 配置文件得到的配置项
+//   - source: Gitea 存储库源名称
+//   - target: 推送目标存储库源名称
+//   - interval: 定时同步间隔，例如 '8h0m0s'
+//   - statusOnly: 只列出同步状态，不创建或更新推送镜像
+func SetupServerMirrors(config *general.Config, source, target, interval string, statusOnly bool) {
+	sources := general.GetSources(config)
+	targetSource, ok := sources[target]
+	if !ok {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Source %s is not configured", target))
+		return
+	}
+	var targetToken []byte
+	if !statusOnly {
+		token, err := general.GetSourceToken(targetSource)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		targetToken = token
+		defer general.ClearPassword(targetToken)
+	}
+	if !statusOnly && len(targetToken) == 0 {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Source %s has no token, please set 'token' in [source.%s] or run 'curator auth login %s'", target, target, target))
+		return
+	}
+	intervalDuration, err := time.ParseDuration(interval)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 创建 Gitea 的存储库托管平台对象
+	giteaSource, ok := sources[source]
+	if !ok {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), fmt.Errorf("Source %s is not configured", source))
+		return
+	}
+	provider, err := general.NewProvider(giteaSource)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	defer provider.Close()
+
+	// 读取推送镜像记录
+	records, err := general.ReadPushMirrorRecords(general.PushMirrorFile)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 只处理使用 Gitea 存储库源的存储库
+	var repoNames []string
+	for _, repoName := range config.Git.Repos {
+		if belongsToSource(config, repoName, source) {
+			repoNames = append(repoNames, repoName)
+		}
+	}
+	sort.Strings(repoNames)
+
+	// 输出基础信息
+	color.Printf("%s Push mirror from %s to %s: %d repositories\n", general.InfoText("INFO:"), general.FgGreenText(source), general.FgGreenText(target), len(repoNames))
+	if !statusOnly {
+		color.Printf("%s Sync interval: %s, sync on commit\n", general.InfoText("INFO:"), general.PrimaryText(intervalDuration))
+	}
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+
+	for _, repoName := range repoNames {
+		actionPrint := color.Sprintf("%s Mirroring %s: ", general.RunFlag, general.FgCyanText(repoName))
+		general.WaitSpinner.Prefix = actionPrint
+		general.WaitSpinner.Start()
+
+		address := "https://" + targetSource.Url + "/" + targetSource.Username + "/" + general.RepoBaseName(repoName) + ".git"
+		mirrorKey := provider.PushMirrorKey(general.RepoBaseName(repoName), address)
+		target := pushMirrorTarget{
+			address:      address,
+			username:     targetSource.Username,
+			token:        targetToken,
+			interval:     intervalDuration,
+			syncOnCommit: true,
+			record:       records[mirrorKey],
+		}
+		pushMirror, action, err := ensurePushMirror(provider, general.RepoBaseName(repoName), target, statusOnly)
+		general.WaitSpinner.Stop()
+
+		// 新推送镜像创建后立即记录，即使删除旧的推送镜像失败，下次运行也不会再创建新的推送镜像
+		if action == "created" || action == "updated" {
+			records[mirrorKey] = general.PushMirrorRecord{Fingerprint: general.PushMirrorFingerprint(target.username, target.token), RemoteName: pushMirror.RemoteName}
+			if err := general.WritePushMirrorRecords(general.PushMirrorFile, records); err != nil {
+				fileName, lineNo := general.GetCallerInfo()
+				color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			}
+		}
+		if err != nil {
+			if pushMirror != nil {
+				color.Printf("%s%s %s\n", actionPrint, general.SuccessFlag, general.SuccessText("Push mirror ", action, " as ", pushMirror.RemoteName))
+			}
+			color.Printf("%s%s %s\n", actionPrint, general.ErrorFlag, general.DangerText(err))
+			continue
+		}
+
+		// 输出操作和同步状态
+		switch action {
+		case "created", "updated":
+			color.Printf("%s%s %s\n", actionPrint, general.SuccessFlag, general.SuccessText("Push mirror ", action))
+		case "cleaned":
+			color.Printf("%s%s %s\n", actionPrint, general.SuccessFlag, general.SuccessText("Stale push mirrors deleted"))
+		case "missing":
+			color.Printf("%s%s %s\n", actionPrint, general.WarningFlag, general.WarnText("No push mirror to ", address))
+			continue
+		default:
+			color.Printf("%s%s %s\n", actionPrint, general.FgBlueText(general.LatestFlag), general.SecondaryText("Push mirror exists"))
+		}
+		length := len(general.RunFlag) + len("Mirroring") // 同步状态缩进长度
+		color.Printf("%s%s %s: %s\n", strings.Repeat(" ", length), general.JoinerIng, general.FgMagentaText("remote"), general.SecondaryText(pushMirror.RemoteName, " ", pushMirror.RemoteAddress))
+		lastSync := pushMirror.LastUpdate
+		if lastSync == "" || strings.HasPrefix(lastSync, "0001-01-01") {
+			lastSync = "never"
+		}
+		if pushMirror.LastError != "" {
+			color.Printf("%s%s %s: %s %s\n", strings.Repeat(" ", length), general.JoinerFinish, general.FgMagentaText("last sync"), general.SecondaryText(lastSync), general.DangerText(strings.TrimSpace(pushMirror.LastError)))
+		} else {
+			color.Printf("%s%s %s: %s\n", strings.Repeat(" ", length), general.JoinerFinish, general.FgMagentaText("last sync"), general.SecondaryText(lastSync))
+		}
+
+		// 添加一个延时，使输出更加顺畅
+		general.Delay(general.DelayTime)
+	}
+}
+
+// pushMirrorTarget 推送镜像的期望设置
+type pushMirrorTarget struct {
+	address      string                   // 推送目标存储库的 HTTPS 地址
+	username     string                   // 推送目标的用户名
+	token        []byte                   // 推送目标的访问令牌
+	interval     time.Duration            // 定时同步间隔
+	syncOnCommit bool                     // 是否在推送提交时同步
+	record       general.PushMirrorRecord // 上次创建推送镜像时的记录，为空表示未知
+}
+
+// ensurePushMirror 确保存储库有推送到指定地址的推送镜像，且同步间隔、推送时同步和凭据与期望设置一致
+//
+//   - Gitea 不支持修改推送镜像，需要更新时先创建新的推送镜像，成功后再删除旧的（包括重复的）推送镜像
+//   - Gitea 不返回推送镜像的凭据，凭据指纹与记录的不一致（包括没有记录）时视为访问令牌已变化
+//   - 记录的推送镜像仍然有效时保留它，只删除推送到同一地址的其他推送镜像（上次删除失败留下的旧推送镜像）
+//   - 新推送镜像已创建但删除旧推送镜像失败时，同时返回新推送镜像、执行的操作和错误信息，调用方仍应记录新推送镜像
+//
+// 参数：
+//   - provider: Gitea 的存储库托管平台对象
+//   - repoName: 存储库名
+//   - target: 推送镜像的期望设置
+//   - statusOnly: 只查询，不创建、更新或删除
+//
+// 返回：
+//   - 推送镜像
+//   - 执行的操作，'created'、'updated'、'cleaned'（删除了多余的推送镜像）、'exists' 或 'missing'（statusOnly 时不存在）
+//   - 错误信息
+func ensurePushMirror(provider *general.Provider, repoName string, target pushMirrorTarget, statusOnly bool) (*general.PushMirror, string, error) {
+	pushMirrors, err := provider.ListPushMirrors(repoName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// 推送到同一地址的已有推送镜像，以及其中记录的推送镜像（没有记录远程名称时只有一个推送镜像才能确定）
+	var oldMirrors []general.PushMirror
+	currentIndex := -1
+	for _, pushMirror := range pushMirrors {
+		if sameMirrorAddress(pushMirror.RemoteAddress, target.address) {
+			if pushMirror.RemoteName == target.record.RemoteName {
+				currentIndex = len(oldMirrors)
+			}
+			oldMirrors = append(oldMirrors, pushMirror)
+		}
+	}
+	if target.record.RemoteName == "" && len(oldMirrors) == 1 {
+		currentIndex = 0
+	}
+	if len(oldMirrors) == 0 {
+		if statusOnly {
+			return nil, "missing", nil
+		}
+	} else if statusOnly {
+		return &oldMirrors[max(currentIndex, 0)], "exists", nil
+	} else if currentIndex >= 0 {
+		currentMirror := oldMirrors[currentIndex]
+		currentInterval, _ := time.ParseDuration(currentMirror.Interval)
+		upToDate := currentInterval == target.interval &&
+			currentMirror.SyncOnCommit == target.syncOnCommit &&
+			target.record.Fingerprint == general.PushMirrorFingerprint(target.username, target.token)
+		if upToDate {
+			staleMirrors := slices.Delete(slices.Clone(oldMirrors), currentIndex, currentIndex+1)
+			if len(staleMirrors) == 0 {
+				return &currentMirror, "exists", nil
+			}
+			if err := deletePushMirrors(provider, repoName, staleMirrors); err != nil {
+				return &currentMirror, "exists", err
+			}
+			return &currentMirror, "cleaned", nil
+		}
+	}
+
+	// 先创建新的推送镜像，失败时保留旧的推送镜像
+	if err := provider.CreatePushMirror(repoName, target.address, target.username, target.token, target.interval.String(), target.syncOnCommit); err != nil {
+		return nil, "", err
+	}
+	pushMirrors, err = provider.ListPushMirrors(repoName)
+	if err != nil {
+		return nil, "", err
+	}
+	var newMirror *general.PushMirror
+	for index, pushMirror := range pushMirrors {
+		isOld := slices.ContainsFunc(oldMirrors, func(oldMirror general.PushMirror) bool { return oldMirror.RemoteName == pushMirror.RemoteName })
+		if !isOld && sameMirrorAddress(pushMirror.RemoteAddress, target.address) {
+			newMirror = &pushMirrors[index]
+			break
+		}
+	}
+	if newMirror == nil {
+		return nil, "", fmt.Errorf("Push mirror to %s not found after creation", target.address)
+	}
+
+	// 删除旧的推送镜像
+	if len(oldMirrors) == 0 {
+		return newMirror, "created", nil
+	}
+	if err := deletePushMirrors(provider, repoName, oldMirrors); err != nil {
+		return newMirror, "updated", err
+	}
+	return newMirror, "updated", nil
+}
+
+// deletePushMirrors 删除推送镜像，某个删除失败时继续删除其余的
+//
+// 参数：
+//   - provider: Gitea 的存储库托管平台对象
+//   - repoName: 存储库名
+//   - pushMirrors: 待删除的推送镜像
+//
+// 返回：
+//   - 错误信息，包含所有删除失败的推送镜像
+func deletePushMirrors(provider *general.Provider, repoName string, pushMirrors []general.PushMirror) error {
+	var errs []error
+	for _, pushMirror := range pushMirrors {
+		if err := provider.DeletePushMirror(repoName, pushMirror.RemoteName); err != nil {
+			errs = append(errs, fmt.Errorf("Failed to delete the old push mirror %s: %s", pushMirror.RemoteName, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sameMirrorAddress 判断两个推送镜像地址是否相同，忽略 '.git' 后缀
+//
+// 参数：
+//   - address: 推送镜像地址
+//   - other: 另一个推送镜像地址
+//
+// 返回：
+//   - 相同返回 true，否则返回 false
+func sameMirrorAddress(address, other string) bool {
+	return strings.TrimSuffix(address, ".git") == strings.TrimSuffix(other, ".git")
+}
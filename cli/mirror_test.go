@@ -0,0 +1,261 @@
+/*
+File: mirror_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 17:46:20
+
+Description: 使用 Gitea 的 HTTP 替身测试推送镜像的创建和更新
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yhyj/curator/general"
+)
+
+// fakeGitea 模拟 Gitea 推送镜像 API 的 HTTP 替身
+type fakeGitea struct {
+	mutex      sync.Mutex
+	mirrors    []general.PushMirror // 已有的推送镜像
+	passwords  map[string]string    // 推送镜像远程名称到访问令牌的映射
+	nextId     int                  // 下一个推送镜像的编号
+	failCreate bool                 // 创建推送镜像时返回错误
+	failDelete bool                 // 删除推送镜像时返回错误
+	requests   []string             // 收到的修改请求，格式为 '<方法> <推送镜像远程名称>'
+}
+
+// ServeHTTP 实现 http.Handler 接口
+func (f *fakeGitea) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	const prefix = "/api/v1/repos/ops/tools/push_mirrors"
+	path := request.URL.Path
+	switch {
+	case request.Method == http.MethodGet && path == prefix:
+		json.NewEncoder(writer).Encode(f.mirrors)
+	case request.Method == http.MethodPost && path == prefix:
+		if f.failCreate {
+			writer.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(writer).Encode(map[string]string{"message": "create failed"})
+			return
+		}
+		var body struct {
+			RemoteAddress  string `json:"remote_address"`
+			RemotePassword string `json:"remote_password"`
+			Interval       string `json:"interval"`
+			SyncOnCommit   bool   `json:"sync_on_commit"`
+		}
+		json.NewDecoder(request.Body).Decode(&body)
+		f.nextId++
+		remoteName := fmt.Sprintf("remote_mirror_%d", f.nextId)
+		f.mirrors = append(f.mirrors, general.PushMirror{RemoteName: remoteName, RemoteAddress: body.RemoteAddress, Interval: body.Interval, SyncOnCommit: body.SyncOnCommit})
+		f.passwords[remoteName] = body.RemotePassword
+		f.requests = append(f.requests, "POST "+remoteName)
+		writer.WriteHeader(http.StatusCreated)
+	case request.Method == http.MethodDelete && strings.HasPrefix(path, prefix+"/"):
+		if f.failDelete {
+			writer.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(writer).Encode(map[string]string{"message": "delete failed"})
+			return
+		}
+		remoteName := strings.TrimPrefix(path, prefix+"/")
+		for index, mirror := range f.mirrors {
+			if mirror.RemoteName == remoteName {
+				f.mirrors = append(f.mirrors[:index], f.mirrors[index+1:]...)
+				f.requests = append(f.requests, "DELETE "+remoteName)
+				writer.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		writer.WriteHeader(http.StatusNotFound)
+	default:
+		writer.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// newTestGitea 启动 Gitea 替身，已有推送镜像的访问令牌为 'old-token'
+func newTestGitea(t *testing.T, mirrors ...general.PushMirror) (*fakeGitea, *general.Provider) {
+	t.Helper()
+	gitea := &fakeGitea{mirrors: mirrors, passwords: make(map[string]string), nextId: len(mirrors)}
+	for _, mirror := range mirrors {
+		gitea.passwords[mirror.RemoteName] = "old-token"
+	}
+	server := httptest.NewServer(gitea)
+	t.Cleanup(server.Close)
+
+	provider, err := general.NewProvider(general.SourceConfig{Type: "gitea", Api: server.URL + "/api/v1", Username: "ops", Token: "gitea-token", Proxy: "direct"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gitea, provider
+}
+
+const testMirrorAddress = "https://github.com/ops/tools.git" // 测试用的推送目标地址
+
+// newTestTarget 创建推送镜像的期望设置，访问令牌为 token，记录的指纹对应 'old-token'
+func newTestTarget(token string) pushMirrorTarget {
+	return pushMirrorTarget{
+		address:      testMirrorAddress,
+		username:     "ops",
+		token:        []byte(token),
+		interval:     8 * time.Hour,
+		syncOnCommit: true,
+		record:       general.PushMirrorRecord{Fingerprint: general.PushMirrorFingerprint("ops", []byte("old-token"))},
+	}
+}
+
+// existingMirror 推送到测试地址的已有推送镜像
+func existingMirror(interval string, syncOnCommit bool) general.PushMirror {
+	return general.PushMirror{RemoteName: "remote_mirror_1", RemoteAddress: strings.TrimSuffix(testMirrorAddress, ".git"), Interval: interval, SyncOnCommit: syncOnCommit}
+}
+
+// TestEnsurePushMirrorCreate 不存在推送镜像时创建
+func TestEnsurePushMirrorCreate(t *testing.T) {
+	gitea, provider := newTestGitea(t)
+	target := newTestTarget("new-token")
+	target.record = general.PushMirrorRecord{}
+
+	pushMirror, action, err := ensurePushMirror(provider, "tools", target, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action != "created" || pushMirror.RemoteName != "remote_mirror_1" {
+		t.Errorf("action = %s, mirror = %+v, want created remote_mirror_1", action, pushMirror)
+	}
+	if !pushMirror.SyncOnCommit || pushMirror.Interval != "8h0m0s" || gitea.passwords["remote_mirror_1"] != "new-token" {
+		t.Errorf("mirror = %+v with token %q, want the target settings", pushMirror, gitea.passwords["remote_mirror_1"])
+	}
+}
+
+// TestEnsurePushMirrorExists 所有设置都一致时不修改
+func TestEnsurePushMirrorExists(t *testing.T) {
+	gitea, provider := newTestGitea(t, existingMirror("8h0m0s", true))
+
+	_, action, err := ensurePushMirror(provider, "tools", newTestTarget("old-token"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action != "exists" || len(gitea.requests) != 0 {
+		t.Errorf("action = %s, requests = %v, want exists without changes", action, gitea.requests)
+	}
+}
+
+// TestEnsurePushMirrorUpdate 同步间隔、推送时同步或访问令牌不同时先创建再删除
+func TestEnsurePushMirrorUpdate(t *testing.T) {
+	for name, testCase := range map[string]struct {
+		mirror general.PushMirror
+		target pushMirrorTarget
+	}{
+		"interval":       {existingMirror("1h0m0s", true), newTestTarget("old-token")},
+		"sync_on_commit": {existingMirror("8h0m0s", false), newTestTarget("old-token")},
+		"token":          {existingMirror("8h0m0s", true), newTestTarget("new-token")},
+		"no fingerprint": {existingMirror("8h0m0s", true), func() pushMirrorTarget {
+			target := newTestTarget("old-token")
+			target.record = general.PushMirrorRecord{}
+			return target
+		}()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			gitea, provider := newTestGitea(t, testCase.mirror)
+
+			pushMirror, action, err := ensurePushMirror(provider, "tools", testCase.target, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if action != "updated" || pushMirror.RemoteName != "remote_mirror_2" {
+				t.Errorf("action = %s, mirror = %+v, want updated remote_mirror_2", action, pushMirror)
+			}
+			if want := []string{"POST remote_mirror_2", "DELETE remote_mirror_1"}; !reflect.DeepEqual(gitea.requests, want) {
+				t.Errorf("requests = %v, want %v", gitea.requests, want)
+			}
+			if len(gitea.mirrors) != 1 || gitea.passwords[gitea.mirrors[0].RemoteName] != string(testCase.target.token) {
+				t.Errorf("mirrors = %+v, want only the new mirror", gitea.mirrors)
+			}
+		})
+	}
+}
+
+// TestEnsurePushMirrorCreateFailed 创建新推送镜像失败时保留旧的推送镜像
+func TestEnsurePushMirrorCreateFailed(t *testing.T) {
+	gitea, provider := newTestGitea(t, existingMirror("1h0m0s", true))
+	gitea.failCreate = true
+
+	if _, _, err := ensurePushMirror(provider, "tools", newTestTarget("old-token"), false); err == nil {
+		t.Fatal("ensure succeeded, want the create error")
+	}
+	if len(gitea.requests) != 0 || len(gitea.mirrors) != 1 || gitea.mirrors[0].RemoteName != "remote_mirror_1" {
+		t.Errorf("requests = %v, mirrors = %+v, want the old mirror kept", gitea.requests, gitea.mirrors)
+	}
+}
+
+// TestEnsurePushMirrorDuplicates 推送到同一地址的重复推送镜像在更新时一并删除
+func TestEnsurePushMirrorDuplicates(t *testing.T) {
+	duplicate := existingMirror("8h0m0s", true)
+	duplicate.RemoteName = "remote_mirror_0"
+	gitea, provider := newTestGitea(t, duplicate, existingMirror("8h0m0s", true))
+
+	_, action, err := ensurePushMirror(provider, "tools", newTestTarget("old-token"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action != "updated" || len(gitea.mirrors) != 1 {
+		t.Errorf("action = %s, mirrors = %+v, want a single updated mirror", action, gitea.mirrors)
+	}
+}
+
+// TestEnsurePushMirrorDeleteFailed 删除旧推送镜像失败时仍返回新推送镜像，按记录再次运行时只删除旧的推送镜像而不再创建
+func TestEnsurePushMirrorDeleteFailed(t *testing.T) {
+	gitea, provider := newTestGitea(t, existingMirror("8h0m0s", true))
+	gitea.failDelete = true
+
+	target := newTestTarget("new-token")
+	pushMirror, action, err := ensurePushMirror(provider, "tools", target, false)
+	if err == nil || action != "updated" || pushMirror == nil || pushMirror.RemoteName != "remote_mirror_2" {
+		t.Fatalf("mirror = %+v, action = %s, err = %v, want updated remote_mirror_2 with a delete error", pushMirror, action, err)
+	}
+
+	// 调用方记录的新推送镜像
+	target.record = general.PushMirrorRecord{Fingerprint: general.PushMirrorFingerprint(target.username, target.token), RemoteName: pushMirror.RemoteName}
+	gitea.failDelete = false
+	gitea.requests = nil
+	pushMirror, action, err = ensurePushMirror(provider, "tools", target, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action != "cleaned" || pushMirror.RemoteName != "remote_mirror_2" {
+		t.Errorf("mirror = %+v, action = %s, want cleaned remote_mirror_2", pushMirror, action)
+	}
+	if want := []string{"DELETE remote_mirror_1"}; !reflect.DeepEqual(gitea.requests, want) {
+		t.Errorf("requests = %v, want %v", gitea.requests, want)
+	}
+	if len(gitea.mirrors) != 1 || gitea.passwords[gitea.mirrors[0].RemoteName] != "new-token" {
+		t.Errorf("mirrors = %+v, want only the new mirror", gitea.mirrors)
+	}
+}
+
+// TestEnsurePushMirrorStatusOnly 只查询时不创建或更新
+func TestEnsurePushMirrorStatusOnly(t *testing.T) {
+	gitea, provider := newTestGitea(t)
+	if _, action, err := ensurePushMirror(provider, "tools", newTestTarget("new-token"), true); err != nil || action != "missing" {
+		t.Errorf("action = %s, err = %v, want missing", action, err)
+	}
+
+	gitea.mirrors = []general.PushMirror{existingMirror("1h0m0s", false)}
+	if _, action, err := ensurePushMirror(provider, "tools", newTestTarget("new-token"), true); err != nil || action != "exists" {
+		t.Errorf("action = %s, err = %v, want exists", action, err)
+	}
+	if len(gitea.requests) != 0 {
+		t.Errorf("requests = %v, want none", gitea.requests)
+	}
+}
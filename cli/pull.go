@@ -10,11 +10,12 @@ Description: 子命令 'pull' 的实现
 package cli
 
 import (
-	"path/filepath"
+	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/gookit/color"
 	"github.com/yhyj/curator/general"
 )
@@ -24,12 +25,13 @@ import (
 // 参数：
 //   - config: 解析 toml 配置文件得到的配置项
 //   - source: 远端存储库源，支持 'github' 和 'gitea'，默认为 'github'
-func RollingPullRepos(config *general.Config, source string) {
+//   - showChanges: 是否列出拉取到的提交和文件变更统计
+func RollingPullRepos(config *general.Config, source string, showChanges bool) {
 	// 为已存在的本地存储库计数
 	totalNum := len(config.Git.Repos) // 总存储库数
 	clonedRepo := make([]string, 0)   // 已 Clone 存储库
 	for _, repoName := range config.Git.Repos {
-		repoPath := filepath.Join(config.Storage.Path, repoName) // 本地存储库路径
+		repoPath := general.GetRepoPath(config, repoName) // 本地存储库路径
 		if general.FileExist(repoPath) {
 			isRepo, _, _ := general.IsLocalRepo(repoPath)
 			if isRepo {
@@ -44,7 +46,7 @@ func RollingPullRepos(config *general.Config, source string) {
 	// 输出基础信息
 	negatives := strings.Builder{}
 	negatives.WriteString(color.Sprintf("%s Pull repository from %s: %d/%d cloned\n", general.InfoText("INFO:"), general.FgGreenText(source), len(clonedRepo), totalNum))
-	negatives.WriteString(color.Sprintf("%s Repository root: %s\n", general.InfoText("INFO:"), general.PrimaryText(config.Storage.Path)))
+	negatives.WriteString(color.Sprintf("%s Repository root: %s (%s)\n", general.InfoText("INFO:"), general.PrimaryText(config.Storage.Path), general.FgGreenText(storageMode(config))))
 
 	// 让用户选择需要 Pull 的存储库
 	selectedRepos, err := general.MultipleSelectionFilter(config.Git.Repos, clonedRepo, negatives.String())
@@ -67,10 +69,10 @@ func RollingPullRepos(config *general.Config, source string) {
 	// 遍历所选存储库名
 	for _, repoName := range selectedRepos {
 		// 构建本地存储库路径
-		repoPath := filepath.Join(config.Storage.Path, repoName)
+		repoPath := general.GetRepoPath(config, repoName)
 
 		// Pull
-		pull(config, repoPath, repoName)
+		pull(config, repoPath, repoName, showChanges)
 
 		// 添加一个延时，使输出更加顺畅
 		general.Delay(general.DelayTime)
@@ -83,9 +85,23 @@ func RollingPullRepos(config *general.Config, source string) {
 //   - config: 配置项目
 //   - path: 本地存储库路径
 //   - name: 存储库名
-func pull(config *general.Config, path, name string) {
+//   - showChanges: 是否列出拉取到的提交和文件变更统计
+func pull(config *general.Config, path, name string, showChanges bool) {
+	// 检测存储模式，避免拼写错误时静默使用工作树模式
+	if err := general.CheckStorageMode(config.Storage.Mode); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
 	// 获取公钥
-	publicKeys, err := general.GetPublicKeysByGit(config.SSH.RsaFile)
+	publicKeys, err := general.GetSSHAuth(config)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	// 获取提交签名校验器
+	verifier, err := general.NewSignatureVerifier(config)
 	if err != nil {
 		fileName, lineNo := general.GetCallerInfo()
 		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
@@ -100,9 +116,36 @@ func pull(config *general.Config, path, name string) {
 	// Pull 前检测本地存储库是否存在
 	if general.FileExist(path) {
 		isRepo, repo, headRef := general.IsLocalRepo(path)
-		if isRepo { // 本地存储库可以 Pull
-			// 开始 Pull
-			worktree, leftCommit, rightCommit, err := general.PullRepo(repo, publicKeys)
+		if isRepo && config.Storage.Mode == general.MirrorMode { // 裸镜像存储库拉取所有引用
+			// HEAD 指向的分支在远端被删除后无法解析
+			if headRef == nil {
+				general.WaitSpinner.Stop()
+				color.Printf("%s%s %s\n", actionPrint, general.ErrorFlag, general.DangerText("HEAD of the mirror repository cannot be resolved"))
+				return
+			}
+			leftCommit, rightCommit, err := general.FetchMirrorRepo(repo, publicKeys)
+			general.WaitSpinner.Stop()
+			if err != nil {
+				if err == git.NoErrAlreadyUpToDate {
+					color.Printf("%s%s %s %s\n", actionPrint, general.FgBlueText(general.LatestFlag), general.SecondaryText("Already up-to-date"), general.SecondaryText("[", headRef.Name().Short(), "]"))
+				} else {
+					color.Printf("%s", actionPrint)
+					fileName, lineNo := general.GetCallerInfo()
+					color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+				}
+			} else {
+				color.Printf("%s%s %s --> %s %s\n", actionPrint, general.SuccessFlag, general.FgBlueText(leftCommit.Hash.String()[:6]), general.FgGreenText(rightCommit.Hash.String()[:6]), general.SecondaryText("[", headRef.Name().Short(), "]"))
+				recordPull(name, path, leftCommit, rightCommit)
+				if showChanges && leftCommit.Hash != rightCommit.Hash {
+					if err := printChanges(leftCommit, rightCommit); err != nil {
+						fileName, lineNo := general.GetCallerInfo()
+						color.Printf("%s%s %s %s %s\n", changesIndent(), general.JoinerFinish, general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+					}
+				}
+			}
+		} else if isRepo { // 本地存储库可以 Pull
+			// 开始 Pull，revision 固定到某个提交时检出到该提交
+			worktree, leftCommit, rightCommit, err := general.PullRepoToRevision(repo, config.Repo[name].Revision, publicKeys)
 			// Pull 结束
 			if err != nil {
 				if err == git.NoErrAlreadyUpToDate {
@@ -141,7 +184,7 @@ func pull(config *general.Config, path, name string) {
 							} else {
 								// 开始 Pull
 								submoduleRepoHeadRef := general.GetRepoHeadRef(submoduleRepo)
-								_, submoduleLeftCommit, submoduleRightCommit, err := general.PullRepo(submoduleRepo, publicKeys)
+								submoduleWorktree, submoduleLeftCommit, submoduleRightCommit, err := general.PullRepo(submoduleRepo, publicKeys)
 								// Pull 结束
 								if err != nil {
 									if err == git.NoErrAlreadyUpToDate {
@@ -156,6 +199,12 @@ func pull(config *general.Config, path, name string) {
 								} else {
 									general.WaitSpinner.Stop()
 									color.Printf("%s%s %s --> %s %s", subActionPrint, general.SuccessFlag, general.FgBlueText(submoduleLeftCommit.Hash.String()[:6]), general.FgGreenText(submoduleRightCommit.Hash.String()[:6]), general.SecondaryText("[", submoduleRepoHeadRef.Name().Short(), "]"))
+									// 校验子模块新提交的签名，校验结果以换行结束
+									if verifier != nil {
+										color.Println()
+										verifyPull(verifier, submoduleWorktree, submoduleLeftCommit, submoduleRightCommit, submoduleIndent(joiner))
+										continue
+									}
 								}
 							}
 							color.Println() // 当前子模块处理完成，处理下一个子模块
@@ -172,6 +221,18 @@ func pull(config *general.Config, path, name string) {
 				general.WaitSpinner.Stop()
 				color.Printf("%s%s %s --> %s %s\n", actionPrint, general.SuccessFlag, general.FgBlueText(leftCommit.Hash.String()[:6]), general.FgGreenText(rightCommit.Hash.String()[:6]), general.SecondaryText("[", headRef.Name().Short(), "]"))
 
+				// 校验新提交的签名
+				if verifier != nil && !verifyPull(verifier, worktree, leftCommit, rightCommit, changesIndent()) {
+					return
+				}
+				recordPull(name, path, leftCommit, rightCommit)
+				if showChanges {
+					if err := printChanges(leftCommit, rightCommit); err != nil {
+						fileName, lineNo := general.GetCallerInfo()
+						color.Printf("%s%s %s %s %s\n", changesIndent(), general.JoinerFinish, general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+					}
+				}
+
 				// 尝试 Pull 子模块
 				submodules, err := general.GetLocalRepoSubmoduleInfo(worktree)
 				if err != nil {
@@ -203,7 +264,7 @@ func pull(config *general.Config, path, name string) {
 						} else {
 							// 开始 Pull
 							submoduleRepoHeadRef := general.GetRepoHeadRef(submoduleRepo)
-							_, submoduleLeftCommit, submoduleRightCommit, err := general.PullRepo(submoduleRepo, publicKeys)
+							submoduleWorktree, submoduleLeftCommit, submoduleRightCommit, err := general.PullRepo(submoduleRepo, publicKeys)
 							// Pull 结束
 							if err != nil {
 								if err == git.NoErrAlreadyUpToDate {
@@ -218,6 +279,12 @@ func pull(config *general.Config, path, name string) {
 							} else {
 								general.WaitSpinner.Stop()
 								color.Printf("%s%s %s --> %s %s", subActionPrint, general.SuccessFlag, general.FgBlueText(submoduleLeftCommit.Hash.String()[:6]), general.FgGreenText(submoduleRightCommit.Hash.String()[:6]), general.SecondaryText("[", submoduleRepoHeadRef.Name().Short(), "]"))
+								// 校验子模块新提交的签名，校验结果以换行结束
+								if verifier != nil {
+									color.Println()
+									verifyPull(verifier, submoduleWorktree, submoduleLeftCommit, submoduleRightCommit, submoduleIndent(joiner))
+									continue
+								}
 							}
 						}
 						color.Println() // 当前子模块处理完成，处理下一个子模块
@@ -234,3 +301,77 @@ func pull(config *general.Config, path, name string) {
 	}
 
 }
+
+// verifyPull 校验拉取的新提交的签名，reject 模式下存在违规的提交或无法列出新提交时回退到拉取前的提交
+//
+// 参数：
+//   - verifier: 提交签名校验器
+//   - worktree: 本地存储库的 worktree
+//   - leftCommit: 拉取前的提交
+//   - rightCommit: 拉取后的提交
+//   - indent: 输出的缩进
+//
+// 返回：
+//   - 拉取被保留返回 true，被回退返回 false
+func verifyPull(verifier *general.SignatureVerifier, worktree *git.Worktree, leftCommit, rightCommit *object.Commit, indent string) bool {
+	commits, err := general.CommitsBetween(leftCommit, rightCommit)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s%s %s %s %s\n", indent, general.JoinerIng, general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		if verifier.Mode != general.VerifyRejectMode {
+			color.Printf("%s%s %s %s\n", indent, general.JoinerFinish, general.WarningFlag, general.WarnText("pulled commits cannot be verified"))
+			return true
+		}
+		return resetPull(worktree, leftCommit, indent, "pulled commits cannot be verified, reset to")
+	}
+	violations := 0 // 违规的提交数
+	for _, commit := range commits {
+		if _, err := verifier.Verify(commit); err != nil {
+			violations++
+			color.Printf("%s%s %s %s %s %s\n", indent, general.JoinerIng, general.WarningFlag, general.FgYellowText(commit.Hash.String()[:6]), general.WarnText(err), general.SecondaryText("(", general.CommitSubject(commit), ")"))
+		}
+	}
+	if violations == 0 {
+		color.Printf("%s%s %s %s\n", indent, general.JoinerFinish, general.SuccessFlag, general.SecondaryText(len(commits), " commits verified"))
+		return true
+	}
+	if verifier.Mode != general.VerifyRejectMode {
+		color.Printf("%s%s %s %s\n", indent, general.JoinerFinish, general.WarningFlag, general.WarnText(violations, " of ", len(commits), " commits failed signature verification"))
+		return true
+	}
+	return resetPull(worktree, leftCommit, indent, fmt.Sprint(violations, " of ", len(commits), " commits failed signature verification, reset to"))
+}
+
+// resetPull 回退到拉取前的提交，保留未提交的修改
+//
+// 参数：
+//   - worktree: 本地存储库的 worktree
+//   - leftCommit: 拉取前的提交
+//   - indent: 输出的缩进
+//   - reason: 回退原因
+//
+// 返回：
+//   - 总是返回 false，表示拉取未被保留
+func resetPull(worktree *git.Worktree, leftCommit *object.Commit, indent, reason string) bool {
+	if err := worktree.Reset(&git.ResetOptions{Commit: leftCommit.Hash, Mode: git.MergeReset}); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s%s %s %s %s\n", indent, general.JoinerFinish, general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return false
+	}
+	color.Printf("%s%s %s %s %s\n", indent, general.JoinerFinish, general.ErrorFlag, general.DangerText(reason), general.FgBlueText(leftCommit.Hash.String()[:6]))
+	return false
+}
+
+// submoduleIndent 子模块下输出的缩进，与子模块的输出连接符对齐
+//
+// 参数：
+//   - joiner: 子模块的输出连接符
+//
+// 返回：
+//   - 缩进
+func submoduleIndent(joiner string) string {
+	if joiner == general.JoinerIng {
+		return changesIndent() + "│   "
+	}
+	return changesIndent() + "    "
+}
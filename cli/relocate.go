@@ -0,0 +1,207 @@
+/*
+File: relocate.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 19:02:14
+
+Description: 子命令 'relocate' 的实现
+*/
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/yhyj/curator/general"
+)
+
+// RelocateRepos 将已有的本地存储库移动到新的存储布局，并更新配置文件中的 storage.layout
+//
+//   - 在 [repo.<存储库名>] 中单独配置了 path 的存储库不受存储布局影响
+//   - 移动前检查所有目标路径，有目标路径已存在、位于某个存储库之内或与其他存储库的目标路径重叠时不移动任何存储库
+//   - 检查通过后仍有存储库移动失败时，仍然更新 storage.layout，并将这些存储库的 [repo.<存储库名>].path 固定为原路径
+//
+// 参数：
+//   - configFile: 配置文件路径
+//   - layout: 新的存储布局模板
+//   - dryRun: 只报告需要移动的存储库，不实际移动
+func RelocateRepos(configFile, layout string, dryRun bool) {
+	config, err := loadConfig(configFile)
+	if err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+
+	// 新布局下的配置项
+	newConfig := *config
+	newConfig.Storage.Layout = layout
+
+	// 显示项排序
+	repoNames := slices.Clone(config.Git.Repos)
+	sort.Strings(repoNames)
+
+	// 输出基础信息
+	oldLayout := config.Storage.Layout
+	if oldLayout == "" {
+		oldLayout = general.DefaultLayout
+	}
+	color.Printf("%s Relocate repository from %s to %s: %d repositories\n", general.InfoText("INFO:"), general.FgGreenText(oldLayout), general.FgGreenText(layout), len(repoNames))
+	color.Printf("%s Repository root: %s\n", general.InfoText("INFO:"), general.PrimaryText(config.Storage.Path))
+	if dryRun {
+		color.Printf("%s Dry run, no repository will be moved\n", general.InfoText("INFO:"))
+	}
+	color.Println(strings.Repeat(general.Separator1st, general.SeparatorBaseLength))
+
+	// 检查所有目标路径，有冲突时不移动任何存储库
+	moves, conflicts := planRelocation(config, &newConfig, repoNames)
+	if len(conflicts) > 0 {
+		for _, move := range moves {
+			if conflict, ok := conflicts[move.repoName]; ok {
+				actionPrint := color.Sprintf("%s Relocating %s: ", general.RunFlag, general.FgCyanText(move.repoName))
+				color.Printf("%s%s %s\n", actionPrint, general.WarningFlag, general.WarnText(conflict))
+			}
+		}
+		color.Printf("%s %d repositories cannot be relocated, no repository moved\n", general.InfoText("INFO:"), len(conflicts))
+		if !dryRun {
+			color.Printf("Update %s: %s\n", general.PrimaryText(configFile), general.WarnText("storage.layout unchanged, resolve the conflicts above and try again"))
+		}
+		return
+	}
+
+	movedNum := 0
+	var failedMoves []repoMove // 检查通过后仍移动失败的存储库
+	for _, move := range moves {
+		actionPrint := color.Sprintf("%s Relocating %s: ", general.RunFlag, general.FgCyanText(move.repoName))
+		if !dryRun {
+			if err := moveRepo(move.oldPath, move.newPath, config.Storage.Path); err != nil {
+				color.Printf("%s%s %s\n", actionPrint, general.ErrorFlag, general.DangerText(err))
+				failedMoves = append(failedMoves, move)
+				continue
+			}
+		}
+		color.Printf("%s%s %s\n", actionPrint, general.SuccessFlag, general.SecondaryText(move.oldPath, " ", general.Indicator, " ", move.newPath))
+		movedNum++
+	}
+	color.Printf("%s %d repositories relocated, %d failed\n", general.InfoText("INFO:"), movedNum, len(failedMoves))
+
+	// 更新配置文件，移动失败的存储库固定在原路径
+	if dryRun {
+		return
+	}
+	for _, move := range failedMoves {
+		pinnedPath, err := filepath.Rel(config.Storage.Path, move.oldPath)
+		if err != nil {
+			pinnedPath = move.oldPath
+		}
+		if err := general.SetTableValueInConfigFile(configFile, []string{"repo", move.repoName}, "path", pinnedPath); err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			color.Printf("Update %s: %s\n", general.PrimaryText(configFile), general.WarnText("storage.layout unchanged, some repositories have been moved to the new layout"))
+			return
+		}
+		color.Printf("Update %s: %s\n", general.PrimaryText(configFile), general.WarnText("repo.", move.repoName, ".path = ", pinnedPath))
+	}
+	if err := general.SetTableValueInConfigFile(configFile, []string{"storage"}, "layout", layout); err != nil {
+		fileName, lineNo := general.GetCallerInfo()
+		color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+		return
+	}
+	color.Printf("Update %s: %s\n", general.PrimaryText(configFile), general.SuccessText("storage.layout = ", layout))
+}
+
+// repoMove 一个存储库的移动
+type repoMove struct {
+	repoName string // 存储库名
+	oldPath  string // 原路径
+	newPath  string // 新路径
+}
+
+// planRelocation 列出需要移动的存储库并检查所有目标路径
+//
+//   - 目标路径已存在、位于某个本地存储库（包括自身的原路径）之内、或与其他存储库的目标路径相同或互相包含时视为冲突
+//
+// 参数：
+//   - config: 原布局下的配置项
+//   - newConfig: 新布局下的配置项
+//   - repoNames: 存储库名
+//
+// 返回：
+//   - 需要移动的存储库
+//   - 存储库名到冲突原因的映射
+func planRelocation(config, newConfig *general.Config, repoNames []string) ([]repoMove, map[string]string) {
+	var moves []repoMove
+	localRepos := make(map[string]string) // 本地存储库名到当前路径的映射
+	for _, repoName := range repoNames {
+		oldPath := general.GetRepoPath(config, repoName)
+		if !general.FileExist(oldPath) {
+			continue
+		}
+		localRepos[repoName] = oldPath
+		if newPath := general.GetRepoPath(newConfig, repoName); newPath != oldPath {
+			moves = append(moves, repoMove{repoName: repoName, oldPath: oldPath, newPath: newPath})
+		}
+	}
+
+	conflicts := make(map[string]string)
+	for _, move := range moves {
+		if general.FileExist(move.newPath) {
+			conflicts[move.repoName] = move.newPath + " already exists"
+			continue
+		}
+		for _, repoName := range repoNames {
+			repoPath, ok := localRepos[repoName]
+			if ok && isSubPath(move.newPath, repoPath) {
+				conflicts[move.repoName] = move.newPath + " is inside " + repoName + " at " + repoPath
+				break
+			}
+		}
+		if _, ok := conflicts[move.repoName]; ok {
+			continue
+		}
+		for _, other := range moves {
+			if other.repoName != move.repoName && (isSubPath(move.newPath, other.newPath) || isSubPath(other.newPath, move.newPath)) {
+				conflicts[move.repoName] = move.newPath + " overlaps the new path of " + other.repoName + " at " + other.newPath
+				break
+			}
+		}
+	}
+	return moves, conflicts
+}
+
+// isSubPath 检测路径是否与另一路径相同或位于其中
+//
+// 参数：
+//   - path: 待检测的路径
+//   - parent: 上级路径
+//
+// 返回：
+//   - 相同或位于其中返回 true，否则返回 false
+func isSubPath(path, parent string) bool {
+	return path == parent || strings.HasPrefix(path, parent+string(filepath.Separator))
+}
+
+// moveRepo 移动本地存储库，并删除原路径留下的空目录
+//
+// 参数：
+//   - oldPath: 原路径
+//   - newPath: 新路径
+//   - root: 存储目录，删除空目录时不超过该目录
+//
+// 返回：
+//   - 错误信息
+func moveRepo(oldPath, newPath, root string) error {
+	if err := os.MkdirAll(filepath.Dir(newPath), os.ModePerm); err != nil {
+		return err
+	}
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+	general.DeleteEmptyParents(oldPath, root)
+	return nil
+}
@@ -0,0 +1,76 @@
+/*
+File: relocate_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 10:12:37
+
+Description: 测试移动存储库前对目标路径的检查
+*/
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yhyj/curator/general"
+)
+
+// newRelocateConfig 在临时存储目录中创建已克隆的存储库，返回原布局和新布局下的配置项
+func newRelocateConfig(t *testing.T, layout string, repoNames ...string) (*general.Config, *general.Config) {
+	config := &general.Config{}
+	config.Storage.Path = t.TempDir()
+	config.Git.GithubUrl = "github.com"
+	config.Git.GithubUsername = "YHYJ"
+	config.Git.Repos = repoNames
+	for _, repoName := range repoNames {
+		if err := os.MkdirAll(filepath.Join(config.Storage.Path, repoName, ".git"), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newConfig := *config
+	newConfig.Storage.Layout = layout
+	return config, &newConfig
+}
+
+// TestPlanRelocationInsideRepo 目标路径位于某个存储库之内时视为冲突
+func TestPlanRelocationInsideRepo(t *testing.T) {
+	config, newConfig := newRelocateConfig(t, "{owner}/{name}", "YHYJ", "curator")
+	moves, conflicts := planRelocation(config, newConfig, config.Git.Repos)
+	if len(moves) != 2 {
+		t.Fatalf("moves = %+v, want 2", moves)
+	}
+	for _, repoName := range []string{"YHYJ", "curator"} {
+		if !strings.Contains(conflicts[repoName], "is inside YHYJ") {
+			t.Errorf("conflict of %s = %q, want inside YHYJ", repoName, conflicts[repoName])
+		}
+	}
+}
+
+// TestPlanRelocationOverlap 目标路径已存在或与其他存储库的目标路径重叠时视为冲突
+func TestPlanRelocationOverlap(t *testing.T) {
+	config, newConfig := newRelocateConfig(t, "{group}", "tools", "docs", "legacy")
+	newConfig.Repo = map[string]general.RepoConfig{"tools": {Group: "work"}, "docs": {Group: "work"}, "legacy": {Group: "curator"}}
+	if err := os.MkdirAll(filepath.Join(config.Storage.Path, "curator"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	_, conflicts := planRelocation(config, newConfig, config.Git.Repos)
+	if !strings.Contains(conflicts["tools"], "overlaps the new path of docs") || !strings.Contains(conflicts["docs"], "overlaps the new path of tools") {
+		t.Errorf("conflicts = %v, want tools and docs to overlap", conflicts)
+	}
+	if !strings.Contains(conflicts["legacy"], "already exists") {
+		t.Errorf("conflict of legacy = %q, want already exists", conflicts["legacy"])
+	}
+}
+
+// TestPlanRelocationClean 没有冲突时列出所有需要移动的存储库
+func TestPlanRelocationClean(t *testing.T) {
+	config, newConfig := newRelocateConfig(t, "{owner}/{name}", "curator", "tools")
+	config.Git.GithubUsername, newConfig.Git.GithubUsername = "ops", "ops"
+	moves, conflicts := planRelocation(config, newConfig, config.Git.Repos)
+	if len(conflicts) != 0 || len(moves) != 2 || moves[0].newPath != filepath.Join(config.Storage.Path, "ops", "curator") {
+		t.Errorf("moves = %+v, conflicts = %v", moves, conflicts)
+	}
+}
@@ -0,0 +1,33 @@
+/*
+File: adopt.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 19:36:22
+
+Description: 执行子命令 'adopt'
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/yhyj/curator/cli"
+)
+
+// adoptCmd represents the adopt command
+var adoptCmd = &cobra.Command{
+	Use:   "adopt",
+	Short: "Adopt existing clones from the storage directory",
+	Long:  `Scan the storage directory for local repositories that are not in the configuration file, infer their source from the origin url, then add the selected ones to the configuration file and apply the remote conventions.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+
+		cli.AdoptRepos(configFile)
+	},
+}
+
+func init() {
+	adoptCmd.Flags().BoolP("help", "h", false, "help for adopt command")
+	rootCmd.AddCommand(adoptCmd)
+}
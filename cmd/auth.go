@@ -0,0 +1,104 @@
+/*
+File: auth.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 23:41:09
+
+Description: 执行子命令 'auth'
+*/
+
+package cmd
+
+import (
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"github.com/yhyj/curator/cli"
+	"github.com/yhyj/curator/general"
+)
+
+// authCmd represents the auth command
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage credentials in the encrypted credential file",
+	Long:  `Manage source tokens and SSH key passphrases stored in the credential file, which is encrypted with a master passphrase. Stored tokens are used for API requests and HTTPS transports, stored passphrases unlock SSH keys without prompting.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// authLoginCmd represents the auth login command
+var authLoginCmd = &cobra.Command{
+	Use:   "login [source]",
+	Short: "Save a source token or an SSH key passphrase",
+	Long:  `Prompt for the token of the source, verify it with the provider API and save it to the credential file, or with --key prompt for the passphrase of the SSH key, verify it and save it.`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		keyFile, _ := cmd.Flags().GetString("key")
+
+		if source, ok := authTarget(args, keyFile); ok {
+			cli.AuthLogin(configFile, source, keyFile)
+		}
+	},
+}
+
+// authLogoutCmd represents the auth logout command
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout [source]",
+	Short: "Remove a source token or an SSH key passphrase",
+	Long:  `Remove the token of the source, or with --key the passphrase of the SSH key, from the credential file.`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// 解析参数
+		keyFile, _ := cmd.Flags().GetString("key")
+
+		if source, ok := authTarget(args, keyFile); ok {
+			cli.AuthLogout(source, keyFile)
+		}
+	},
+}
+
+// authListCmd represents the auth list command
+var authListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved credentials",
+	Long:  `List the sources and SSH keys that have credentials saved in the credential file, with the credentials masked.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cli.AuthList()
+	},
+}
+
+// authTarget 获取 auth 子命令的操作对象，存储库源名称和 --key 必须且只能指定一个
+//
+// 参数：
+//   - args: 位置参数
+//   - keyFile: --key 参数值
+//
+// 返回：
+//   - 存储库源名称
+//   - 参数是否有效
+func authTarget(args []string, keyFile string) (string, bool) {
+	if (len(args) == 0) == (keyFile == "") {
+		color.Printf("%s %s\n", general.DangerText(general.ErrorInfoFlag), "Specify either a source or --key")
+		return "", false
+	}
+	if len(args) == 0 {
+		return "", true
+	}
+	return args[0], true
+}
+
+func init() {
+	authLoginCmd.Flags().String("key", "", "SSH key file whose passphrase to save")
+	authLogoutCmd.Flags().String("key", "", "SSH key file whose passphrase to remove")
+
+	authLoginCmd.Flags().BoolP("help", "h", false, "help for login command")
+	authLogoutCmd.Flags().BoolP("help", "h", false, "help for logout command")
+	authListCmd.Flags().BoolP("help", "h", false, "help for list command")
+	authCmd.AddCommand(authLoginCmd, authLogoutCmd, authListCmd)
+
+	authCmd.Flags().BoolP("help", "h", false, "help for auth command")
+	rootCmd.AddCommand(authCmd)
+}
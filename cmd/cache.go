@@ -0,0 +1,97 @@
+/*
+File: cache.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 09:40:18
+
+Description: 执行子命令 'cache'
+*/
+
+package cmd
+
+import (
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"github.com/yhyj/curator/cli"
+	"github.com/yhyj/curator/general"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local reference cache",
+	Long:  `Manage the local cache of bare mirrors that clone fetches from before contacting the remote.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// cacheUpdateCmd represents the cache update command
+var cacheUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Create or update cached mirrors",
+	Long:  `Create or update the cached bare mirror of every repository in the configuration file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		sourceFlag, _ := cmd.Flags().GetString("source")
+
+		// 读取配置文件
+		configTree, err := general.GetTomlConfig(configFile)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		// 获取配置项
+		config, err := general.LoadConfigToStruct(configTree)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+
+		cli.UpdateCache(config, sourceFlag)
+	},
+}
+
+// cacheGcCmd represents the cache gc command
+var cacheGcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove stale cached mirrors",
+	Long:  `Remove cached bare mirrors that no longer belong to any repository in the configuration file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+
+		// 读取配置文件
+		configTree, err := general.GetTomlConfig(configFile)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		// 获取配置项
+		config, err := general.LoadConfigToStruct(configTree)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+
+		cli.GcCache(config)
+	},
+}
+
+func init() {
+	cacheUpdateCmd.Flags().String("source", "github", "Specify the data source (github or gitea)")
+
+	cacheUpdateCmd.Flags().BoolP("help", "h", false, "help for update command")
+	cacheGcCmd.Flags().BoolP("help", "h", false, "help for gc command")
+	cacheCmd.AddCommand(cacheUpdateCmd)
+	cacheCmd.AddCommand(cacheGcCmd)
+
+	cacheCmd.Flags().BoolP("help", "h", false, "help for cache command")
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,35 @@
+/*
+File: changes.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 00:44:52
+
+Description: 执行子命令 'changes'
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/yhyj/curator/cli"
+)
+
+// changesCmd represents the changes command
+var changesCmd = &cobra.Command{
+	Use:   "changes",
+	Short: "Review the commits pulled across repositories recently",
+	Long:  `List the commits and a diffstat of everything pulled into each repository since the given time, based on the pull history recorded by the pull command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 解析参数
+		sinceFlag, _ := cmd.Flags().GetString("since")
+
+		cli.ShowChanges(sinceFlag)
+	},
+}
+
+func init() {
+	changesCmd.Flags().String("since", "24h", "Show changes pulled since this time (e.g. 24h, 7d, yesterday, 2006-01-02)")
+
+	changesCmd.Flags().BoolP("help", "h", false, "help for changes command")
+	rootCmd.AddCommand(changesCmd)
+}
@@ -0,0 +1,45 @@
+/*
+File: convert.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 13:58:14
+
+Description: 执行子命令 'convert'
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/yhyj/curator/cli"
+)
+
+// convertCmd represents the convert command
+var convertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert between workspace description formats",
+	Long:  `Convert between the configuration file (curator), repo manifests (repo), vcstool .repos files (vcstool) and myrepos .mrconfig files (mr).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		fromFlag, _ := cmd.Flags().GetString("from")
+		toFlag, _ := cmd.Flags().GetString("to")
+		inputFlag, _ := cmd.Flags().GetString("input")
+		outputFlag, _ := cmd.Flags().GetString("output")
+		sourceFlag, _ := cmd.Flags().GetString("source")
+
+		cli.ConvertConfig(configFile, fromFlag, toFlag, inputFlag, outputFlag, sourceFlag)
+	},
+}
+
+func init() {
+	convertCmd.Flags().String("from", "curator", "Specify the source format (curator, repo, vcstool or mr)")
+	convertCmd.Flags().String("to", "curator", "Specify the target format (curator, repo, vcstool or mr)")
+	convertCmd.Flags().String("input", "", "Specify the source file (default is the configuration file for curator)")
+	convertCmd.Flags().String("output", "", "Specify the target file (default is the configuration file for curator, stdout for others)")
+	convertCmd.Flags().String("source", "github", "Specify the data source for repositories without one")
+
+	convertCmd.Flags().BoolP("help", "h", false, "help for convert command")
+	rootCmd.AddCommand(convertCmd)
+}
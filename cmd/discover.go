@@ -0,0 +1,37 @@
+/*
+File: discover.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 15:08:19
+
+Description: 执行子命令 'discover'
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/yhyj/curator/cli"
+)
+
+// discoverCmd represents the discover command
+var discoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "Discover repositories from the provider API",
+	Long:  `Query the GitHub/Gitea API for the repositories of the source owner, then choose which new ones to add to and which removed ones to drop from the configuration file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		sourceFlag, _ := cmd.Flags().GetString("source")
+
+		cli.DiscoverRepos(configFile, sourceFlag)
+	},
+}
+
+func init() {
+	discoverCmd.Flags().String("source", "github", "Specify the data source")
+
+	discoverCmd.Flags().BoolP("help", "h", false, "help for discover command")
+	rootCmd.AddCommand(discoverCmd)
+}
@@ -0,0 +1,59 @@
+/*
+File: doctor.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 20:08:31
+
+Description: 执行子命令 'doctor'
+*/
+
+package cmd
+
+import (
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"github.com/yhyj/curator/cli"
+	"github.com/yhyj/curator/general"
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the environment, credentials and storage directory",
+	Long:  `Diagnose the configuration file, SSH key, known hosts, source access and storage path, or with --inventory the storage directory against the configured repositories, printing the result of each check with suggested fixes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		inventoryFlag, _ := cmd.Flags().GetBool("inventory")
+
+		if !inventoryFlag {
+			cli.DiagnoseEnvironment(configFile)
+			return
+		}
+
+		// 读取配置文件
+		configTree, err := general.GetTomlConfig(configFile)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		// 获取配置项
+		config, err := general.LoadConfigToStruct(configTree)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+
+		cli.CheckInventory(config)
+	},
+}
+
+func init() {
+	doctorCmd.Flags().Bool("inventory", false, "Compare the storage directory with the configured repositories")
+
+	doctorCmd.Flags().BoolP("help", "h", false, "help for doctor command")
+	rootCmd.AddCommand(doctorCmd)
+}
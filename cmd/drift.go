@@ -0,0 +1,54 @@
+/*
+File: drift.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 16:17:44
+
+Description: 执行子命令 'drift'
+*/
+
+package cmd
+
+import (
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"github.com/yhyj/curator/cli"
+	"github.com/yhyj/curator/general"
+)
+
+// driftCmd represents the drift command
+var driftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Report drift between primary and mirror sources",
+	Long:  `List the branches and tags of every repository on the primary and mirror sources, then report the refs that are missing or point at different commits.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		sourceFlag, _ := cmd.Flags().GetString("source")
+
+		// 读取配置文件
+		configTree, err := general.GetTomlConfig(configFile)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		// 获取配置项
+		config, err := general.LoadConfigToStruct(configTree)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+
+		cli.ReportDrift(config, sourceFlag)
+	},
+}
+
+func init() {
+	driftCmd.Flags().String("source", "github", "Specify the primary data source (github or gitea)")
+
+	driftCmd.Flags().BoolP("help", "h", false, "help for drift command")
+	rootCmd.AddCommand(driftCmd)
+}
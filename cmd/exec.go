@@ -0,0 +1,71 @@
+/*
+File: exec.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 01:48:07
+
+Description: 执行子命令 'exec'
+*/
+
+package cmd
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"github.com/yhyj/curator/cli"
+	"github.com/yhyj/curator/general"
+)
+
+// execCmd represents the exec command
+var execCmd = &cobra.Command{
+	Use:   "exec [flags] -- <command> [args...]",
+	Short: "Run a command in each selected repository",
+	Long:  `Run a command inside the directory of each selected repository, chosen in the selector or by --source, --group or --all, in parallel with --jobs, then print each repository's output and a summary of exit statuses. Exits with status 1 if any command failed.`,
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		var options cli.ExecOptions
+		options.Source, _ = cmd.Flags().GetString("source")
+		options.Group, _ = cmd.Flags().GetString("group")
+		options.All, _ = cmd.Flags().GetBool("all")
+		options.Jobs, _ = cmd.Flags().GetInt("jobs")
+		options.FailFast, _ = cmd.Flags().GetBool("fail-fast")
+		options.Prefix, _ = cmd.Flags().GetBool("prefix")
+
+		// 读取配置文件
+		configTree, err := general.GetTomlConfig(configFile)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		// 获取配置项
+		config, err := general.LoadConfigToStruct(configTree)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+
+		if !cli.ExecRepos(config, args, options) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	execCmd.Flags().String("source", "", "Only run in repositories of this source")
+	execCmd.Flags().String("group", "", "Only run in repositories of this group")
+	execCmd.Flags().Bool("all", false, "Run in all cloned repositories without the selector")
+	execCmd.Flags().IntP("jobs", "j", runtime.NumCPU(), "Number of commands to run in parallel")
+	execCmd.Flags().Bool("fail-fast", false, "Do not start new commands after one fails")
+	execCmd.Flags().Bool("prefix", false, "Prefix each output line with the repository name instead of grouping output")
+
+	execCmd.Flags().BoolP("help", "h", false, "help for exec command")
+	rootCmd.AddCommand(execCmd)
+}
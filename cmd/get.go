@@ -0,0 +1,38 @@
+/*
+File: get.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 18:25:37
+
+Description: 执行子命令 'get'
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/yhyj/curator/cli"
+)
+
+// getCmd represents the get command
+var getCmd = &cobra.Command{
+	Use:   "get <url|owner/name>",
+	Short: "Clone a repository and add it to the configuration file",
+	Long:  `Clone a repository given by SSH/HTTPS url or owner/name shorthand into the storage, run the run_queue, then add it to the configuration file while preserving formatting and comments.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		sourceFlag, _ := cmd.Flags().GetString("source")
+
+		cli.GetRepo(configFile, args[0], sourceFlag)
+	},
+}
+
+func init() {
+	getCmd.Flags().String("source", "github", "Specify the data source for owner/name shorthand")
+
+	getCmd.Flags().BoolP("help", "h", false, "help for get command")
+	rootCmd.AddCommand(getCmd)
+}
@@ -0,0 +1,54 @@
+/*
+File: lock.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 10:31:50
+
+Description: 执行子命令 'lock'
+*/
+
+package cmd
+
+import (
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"github.com/yhyj/curator/cli"
+	"github.com/yhyj/curator/general"
+)
+
+// lockCmd represents the lock command
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Pin every repository to its current commit",
+	Long:  `Record the source, branch and HEAD commit of every cloned repository and its submodules into a lock file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		fileFlag, _ := cmd.Flags().GetString("file")
+
+		// 读取配置文件
+		configTree, err := general.GetTomlConfig(configFile)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		// 获取配置项
+		config, err := general.LoadConfigToStruct(configTree)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+
+		cli.CreateLockFile(config, fileFlag)
+	},
+}
+
+func init() {
+	lockCmd.Flags().String("file", general.LockFile, "Specify lock file")
+
+	lockCmd.Flags().BoolP("help", "h", false, "help for lock command")
+	rootCmd.AddCommand(lockCmd)
+}
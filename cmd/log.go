@@ -0,0 +1,60 @@
+/*
+File: log.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 01:15:20
+
+Description: 执行子命令 'log'
+*/
+
+package cmd
+
+import (
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"github.com/yhyj/curator/cli"
+	"github.com/yhyj/curator/general"
+)
+
+// logCmd represents the log command
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show recent commits across all repositories",
+	Long:  `Walk the commits since the given time on all branches of every cloned repository (optionally including submodules), filter them by author and print them merged chronologically as text, JSON or Markdown, e.g. as a daily standup report.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		authorFlag, _ := cmd.Flags().GetString("author")
+		submodulesFlag, _ := cmd.Flags().GetBool("submodules")
+		formatFlag, _ := cmd.Flags().GetString("format")
+
+		// 读取配置文件
+		configTree, err := general.GetTomlConfig(configFile)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		// 获取配置项
+		config, err := general.LoadConfigToStruct(configTree)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+
+		cli.ShowLog(config, sinceFlag, authorFlag, submodulesFlag, formatFlag)
+	},
+}
+
+func init() {
+	logCmd.Flags().String("since", "yesterday", "Show commits since this time (e.g. 24h, 7d, yesterday, 2006-01-02)")
+	logCmd.Flags().String("author", "", "Only show commits whose author name or email contains this ('me' matches your git user.email exactly)")
+	logCmd.Flags().Bool("submodules", false, "Include commits of submodules")
+	logCmd.Flags().String("format", cli.TextFormat, "Output format (text, json or markdown)")
+
+	logCmd.Flags().BoolP("help", "h", false, "help for log command")
+	rootCmd.AddCommand(logCmd)
+}
@@ -0,0 +1,89 @@
+/*
+File: manifest.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 11:36:58
+
+Description: 执行子命令 'manifest'
+*/
+
+package cmd
+
+import (
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"github.com/yhyj/curator/cli"
+	"github.com/yhyj/curator/general"
+)
+
+// manifestCmd represents the manifest command
+var manifestCmd = &cobra.Command{
+	Use:   "manifest",
+	Short: "Import or export repo manifests",
+	Long:  `Convert between Google repo tool XML manifests and the configuration file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// manifestImportCmd represents the manifest import command
+var manifestImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a manifest into the configuration file",
+	Long:  `Add the remotes of a repo manifest as sources and its projects as repositories to the configuration file.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		manifestUrlFlag, _ := cmd.Flags().GetString("manifest-url")
+
+		cli.ImportManifestFile(configFile, args[0], manifestUrlFlag)
+	},
+}
+
+// manifestExportCmd represents the manifest export command
+var manifestExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the configuration file as a manifest",
+	Long:  `Render the sources and repositories in the configuration file as a repo manifest.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		sourceFlag, _ := cmd.Flags().GetString("source")
+		outputFlag, _ := cmd.Flags().GetString("output")
+
+		// 读取配置文件
+		configTree, err := general.GetTomlConfig(configFile)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		// 获取配置项
+		config, err := general.LoadConfigToStruct(configTree)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+
+		cli.ExportManifestFile(config, sourceFlag, outputFlag)
+	},
+}
+
+func init() {
+	manifestImportCmd.Flags().String("manifest-url", "", "Specify the url of the manifest repository to resolve relative fetch urls")
+
+	manifestExportCmd.Flags().String("source", "github", "Specify the default remote of the manifest")
+	manifestExportCmd.Flags().String("output", "", "Write the manifest to a file instead of stdout")
+
+	manifestImportCmd.Flags().BoolP("help", "h", false, "help for import command")
+	manifestExportCmd.Flags().BoolP("help", "h", false, "help for export command")
+	manifestCmd.AddCommand(manifestImportCmd)
+	manifestCmd.AddCommand(manifestExportCmd)
+
+	manifestCmd.Flags().BoolP("help", "h", false, "help for manifest command")
+	rootCmd.AddCommand(manifestCmd)
+}
@@ -0,0 +1,40 @@
+/*
+File: migrate.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 17:26:13
+
+Description: 执行子命令 'migrate'
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/yhyj/curator/cli"
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate <repo>",
+	Short: "Migrate a repository from one source to another",
+	Long:  `Migrate a repository to another source via the Gitea migration API (or create and push when unavailable), then update the origin of the local repository and the configuration file.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		fromFlag, _ := cmd.Flags().GetString("from")
+		toFlag, _ := cmd.Flags().GetString("to")
+
+		cli.MigrateRepo(configFile, args[0], fromFlag, toFlag)
+	},
+}
+
+func init() {
+	migrateCmd.Flags().String("from", "github", "Specify the data source to migrate from")
+	migrateCmd.Flags().String("to", "gitea", "Specify the data source to migrate to")
+
+	migrateCmd.Flags().BoolP("help", "h", false, "help for migrate command")
+	rootCmd.AddCommand(migrateCmd)
+}
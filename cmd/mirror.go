@@ -0,0 +1,147 @@
+/*
+File: mirror.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 15:40:52
+
+Description: 执行子命令 'mirror'
+*/
+
+package cmd
+
+import (
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"github.com/yhyj/curator/cli"
+	"github.com/yhyj/curator/general"
+)
+
+// mirrorCmd represents the mirror command
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror",
+	Short: "Manage repositories on mirror sources",
+	Long:  `Manage the copies of repositories on mirror sources (GitHub and Gitea mirror each other).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// mirrorEnsureCmd represents the mirror ensure command
+var mirrorEnsureCmd = &cobra.Command{
+	Use:   "ensure",
+	Short: "Create missing repositories on mirror sources",
+	Long:  `Check every repository on each mirror source via the provider API and create the missing ones with the visibility and description of the primary source.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		sourceFlag, _ := cmd.Flags().GetString("source")
+		dryRunFlag, _ := cmd.Flags().GetBool("dry-run")
+
+		// 读取配置文件
+		configTree, err := general.GetTomlConfig(configFile)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		// 获取配置项
+		config, err := general.LoadConfigToStruct(configTree)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+
+		cli.EnsureMirrorRepos(config, sourceFlag, dryRunFlag)
+	},
+}
+
+// mirrorPushCmd represents the mirror push command
+var mirrorPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Push origin branches and tags to mirror sources",
+	Long:  `Fetch origin of every local repository and push its branches and tags to the mirror sources, rejecting non-fast-forward updates unless forced.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		sourceFlag, _ := cmd.Flags().GetString("source")
+		forceFlag, _ := cmd.Flags().GetBool("force")
+		dryRunFlag, _ := cmd.Flags().GetBool("dry-run")
+
+		// 读取配置文件
+		configTree, err := general.GetTomlConfig(configFile)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		// 获取配置项
+		config, err := general.LoadConfigToStruct(configTree)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+
+		cli.PushMirrors(config, sourceFlag, forceFlag, dryRunFlag)
+	},
+}
+
+// mirrorServerSetupCmd represents the mirror server-setup command
+var mirrorServerSetupCmd = &cobra.Command{
+	Use:   "server-setup",
+	Short: "Configure server-side push mirrors on Gitea",
+	Long:  `Create or update Gitea push mirrors to the target source for every repository via the Gitea API, then list their last sync status.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		sourceFlag, _ := cmd.Flags().GetString("source")
+		targetFlag, _ := cmd.Flags().GetString("target")
+		intervalFlag, _ := cmd.Flags().GetString("interval")
+		statusFlag, _ := cmd.Flags().GetBool("status")
+
+		// 读取配置文件
+		configTree, err := general.GetTomlConfig(configFile)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		// 获取配置项
+		config, err := general.LoadConfigToStruct(configTree)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+
+		cli.SetupServerMirrors(config, sourceFlag, targetFlag, intervalFlag, statusFlag)
+	},
+}
+
+func init() {
+	mirrorEnsureCmd.Flags().String("source", "github", "Specify the primary data source (github or gitea)")
+	mirrorEnsureCmd.Flags().Bool("dry-run", false, "Only report the repositories to be created")
+
+	mirrorPushCmd.Flags().String("source", "github", "Specify the primary data source (github or gitea)")
+	mirrorPushCmd.Flags().Bool("force", false, "Allow non-fast-forward updates")
+	mirrorPushCmd.Flags().Bool("dry-run", false, "Only report the refs to be pushed")
+
+	mirrorServerSetupCmd.Flags().String("source", "gitea", "Specify the Gitea data source")
+	mirrorServerSetupCmd.Flags().String("target", "github", "Specify the data source to push to")
+	mirrorServerSetupCmd.Flags().String("interval", "8h0m0s", "Specify the sync interval")
+	mirrorServerSetupCmd.Flags().Bool("status", false, "Only list the push mirrors and their last sync status")
+
+	mirrorEnsureCmd.Flags().BoolP("help", "h", false, "help for ensure command")
+	mirrorPushCmd.Flags().BoolP("help", "h", false, "help for push command")
+	mirrorServerSetupCmd.Flags().BoolP("help", "h", false, "help for server-setup command")
+	mirrorCmd.AddCommand(mirrorEnsureCmd)
+	mirrorCmd.AddCommand(mirrorPushCmd)
+	mirrorCmd.AddCommand(mirrorServerSetupCmd)
+
+	mirrorCmd.Flags().BoolP("help", "h", false, "help for mirror command")
+	rootCmd.AddCommand(mirrorCmd)
+}
@@ -26,6 +26,7 @@ var pullCmd = &cobra.Command{
 		configFile, _ := cmd.Flags().GetString("config")
 		// 解析参数
 		sourceFlag, _ := cmd.Flags().GetString("source")
+		changesFlag, _ := cmd.Flags().GetBool("changes")
 
 		// 读取配置文件
 		configTree, err := general.GetTomlConfig(configFile)
@@ -42,12 +43,13 @@ var pullCmd = &cobra.Command{
 			return
 		}
 
-		cli.RollingPullRepos(config, sourceFlag)
+		cli.RollingPullRepos(config, sourceFlag, changesFlag)
 	},
 }
 
 func init() {
 	pullCmd.Flags().String("source", "github", "Specify the data source (github or gitea)")
+	pullCmd.Flags().Bool("changes", false, "List the pulled commits and a diffstat")
 
 	pullCmd.Flags().BoolP("help", "h", false, "help for pull command")
 	rootCmd.AddCommand(pullCmd)
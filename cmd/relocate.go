@@ -0,0 +1,40 @@
+/*
+File: relocate.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 18:57:40
+
+Description: 执行子命令 'relocate'
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/yhyj/curator/cli"
+)
+
+// relocateCmd represents the relocate command
+var relocateCmd = &cobra.Command{
+	Use:   "relocate",
+	Short: "Move existing clones into a new storage layout",
+	Long:  `Move existing local repositories into the paths given by a new storage layout template, then update storage.layout in the configuration file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		layoutFlag, _ := cmd.Flags().GetString("layout")
+		dryRunFlag, _ := cmd.Flags().GetBool("dry-run")
+
+		cli.RelocateRepos(configFile, layoutFlag, dryRunFlag)
+	},
+}
+
+func init() {
+	relocateCmd.Flags().String("layout", "", "Specify the new storage layout, e.g. {source}/{owner}/{name}")
+	relocateCmd.Flags().Bool("dry-run", false, "Only report the repositories to be moved")
+	relocateCmd.MarkFlagRequired("layout")
+
+	relocateCmd.Flags().BoolP("help", "h", false, "help for relocate command")
+	rootCmd.AddCommand(relocateCmd)
+}
@@ -34,6 +34,10 @@ func Execute() {
 }
 
 func init() {
+	// SSH 连接使用 ~/.ssh/config 中的配置，SSH 和 HTTPS 连接使用配置的代理
+	general.InstallSSHConfigTransport()
+	general.InstallProxyTransports()
+
 	rootCmd.PersistentFlags().String("config", general.ConfigFile, "Specify configuration file")
 
 	rootCmd.Flags().BoolP("help", "h", false, "help for curator")
@@ -0,0 +1,56 @@
+/*
+File: sync.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 10:33:07
+
+Description: 执行子命令 'sync'
+*/
+
+package cmd
+
+import (
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+	"github.com/yhyj/curator/cli"
+	"github.com/yhyj/curator/general"
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Synchronize repositories with the lock file",
+	Long:  `Compare local repositories with the lock file and report deviations, or clone and check out the locked commits with --locked.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// 获取配置文件路径
+		configFile, _ := cmd.Flags().GetString("config")
+		// 解析参数
+		fileFlag, _ := cmd.Flags().GetString("file")
+		lockedFlag, _ := cmd.Flags().GetBool("locked")
+
+		// 读取配置文件
+		configTree, err := general.GetTomlConfig(configFile)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+		// 获取配置项
+		config, err := general.LoadConfigToStruct(configTree)
+		if err != nil {
+			fileName, lineNo := general.GetCallerInfo()
+			color.Printf("%s %s %s\n", general.DangerText(general.ErrorInfoFlag), general.SecondaryText("[", fileName, ":", lineNo+1, "]"), err)
+			return
+		}
+
+		cli.SyncLockedRepos(config, fileFlag, lockedFlag)
+	},
+}
+
+func init() {
+	syncCmd.Flags().String("file", general.LockFile, "Specify lock file")
+	syncCmd.Flags().Bool("locked", false, "Clone missing repositories and check out the locked commits")
+
+	syncCmd.Flags().BoolP("help", "h", false, "help for sync command")
+	rootCmd.AddCommand(syncCmd)
+}
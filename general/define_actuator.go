@@ -63,7 +63,7 @@ func RunCommandToOS(command string, args []string) error {
 //   - 错误信息
 func RunCommandToBuffer(command string, args []string) (string, string, error) {
 	// 检查命令是否存在，添加了对 `sudo` 命令的规避，`sudo`命令应独立检测
-	if command == "sudo" {
+	if command == "sudo" && len(args) > 0 {
 		command = args[0]
 		args = args[1:]
 		if _, err := exec.LookPath(command); err != nil {
@@ -71,8 +71,28 @@ func RunCommandToBuffer(command string, args []string) (string, string, error) {
 		}
 	}
 
+	return RunCommandToBufferInDir("", command, args)
+}
+
+// RunCommandToBufferInDir 在指定工作目录中运行命令，将命令的 Stdout 和 Stderr 定向到字节缓冲区
+//
+//   - 只设置子进程的工作目录，不改变当前进程的工作目录，可以并发调用
+//   - 命令原样运行，不像 RunCommandToBuffer 那样规避 `sudo`
+//   - 命令的 Stdout 和 Stderr 末尾自带的换行符已去除
+//
+// 参数：
+//   - dir: 工作目录，为空时使用当前工作目录
+//   - command: 命令
+//   - args: 命令参数（每个以空格分隔的参数作为切片的一个元素）
+//
+// 返回：
+//   - Stdout 缓冲区内容
+//   - Stderr 缓冲区内容
+//   - 错误信息
+func RunCommandToBufferInDir(dir, command string, args []string) (string, string, error) {
 	// 定义命令
 	cmd := exec.Command(command, args...)
+	cmd.Dir = dir
 
 	// 创建字节缓冲区
 	var stdout bytes.Buffer
@@ -10,16 +10,26 @@ Description: 身份认证
 package general
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"net"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/gookit/color"
 	cssh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/net/proxy"
 	"golang.org/x/term"
 )
 
@@ -32,36 +42,66 @@ import (
 //   - ssh 公钥
 //   - 错误信息
 func GetPublicKeysByGit(pemFile string) (*ssh.PublicKeys, error) {
-	if _, err := os.Stat(pemFile); err != nil {
+	pemBytes, err := os.ReadFile(pemFile)
+	if err != nil {
 		return nil, err
 	}
+	defer ClearPassword(pemBytes)
 
 	// 初次尝试，默认密码为空
-	publicKeys, err := ssh.NewPublicKeysFromFile("git", pemFile, "")
-	if err != nil {
-		if err == x509.IncorrectPasswordError || strings.Contains(err.Error(), "empty password") {
-			maxAttempts := 3 // 最大尝试次数
-			for attempts := 0; attempts < maxAttempts; attempts++ {
-				color.Printf("Enter passphrase for key '%s' (%s/%s): ", PrimaryText(pemFile), WarnText(attempts+1), NoticeText(maxAttempts))
-				password, err := term.ReadPassword(int(syscall.Stdin))
-				if err != nil {
-					return nil, err
-				}
-				color.Println() // 换行
-				publicKeys, err := ssh.NewPublicKeysFromFile("git", pemFile, string(password))
-
-				clearPassword(password)
+	signer, err := cssh.ParsePrivateKey(pemBytes)
+	if err == nil {
+		return &ssh.PublicKeys{User: "git", Signer: signer}, nil
+	}
+	var missingErr *cssh.PassphraseMissingError
+	if !errors.As(err, &missingErr) && err != x509.IncorrectPasswordError && !strings.Contains(err.Error(), "empty password") {
+		return nil, err
+	}
 
-				if err == nil {
-					return publicKeys, nil
-				}
-			}
-			return nil, fmt.Errorf("Permission denied (publickey)")
-		} else {
+	// 优先使用凭据文件中保存的密码，凭据文件无法解锁时说明原因后改为询问
+	passphrase, err := LookupPassphrase(pemFile)
+	if err != nil {
+		color.Printf("%s %s\n", WarnText("Saved passphrase is unavailable:"), SecondaryText(err))
+	} else if passphrase != nil {
+		signer, err := cssh.ParsePrivateKeyWithPassphrase(pemBytes, passphrase)
+		ClearPassword(passphrase)
+		if err == nil {
+			return &ssh.PublicKeys{User: "git", Signer: signer}, nil
+		}
+	}
+	maxAttempts := 3 // 最大尝试次数
+	for attempts := 0; attempts < maxAttempts; attempts++ {
+		password, err := ReadPassword(color.Sprintf("Enter passphrase for key '%s' (%s/%s): ", PrimaryText(pemFile), WarnText(attempts+1), NoticeText(maxAttempts)))
+		if err != nil {
 			return nil, err
 		}
+		signer, err := cssh.ParsePrivateKeyWithPassphrase(pemBytes, password)
+
+		ClearPassword(password)
+
+		if err == nil {
+			return &ssh.PublicKeys{User: "git", Signer: signer}, nil
+		}
+	}
+	return nil, fmt.Errorf("Permission denied (publickey)")
+}
+
+// ReadPassword 显示提示并从终端读取不回显的密码
+//
+// 参数：
+//   - prompt: 提示信息
+//
+// 返回：
+//   - 密码，使用后应调用 ClearPassword 清除
+//   - 错误信息
+func ReadPassword(prompt string) ([]byte, error) {
+	color.Print(prompt)
+	password, err := term.ReadPassword(int(syscall.Stdin))
+	if err != nil {
+		return nil, err
 	}
-	return publicKeys, err
+	color.Println() // 换行
+	return password, nil
 }
 
 // GetPublicKeysBySSH 使用 crypto/ssh 模块获取 ssh 公钥
@@ -87,11 +127,270 @@ func GetPublicKeysBySSH(pemFile string) (transport.AuthMethod, error) {
 	return auth, nil
 }
 
-// clearPassword 清除内存中的密码，以增加安全性
+var (
+	sshPort    = 22               // 存储库源的 ssh 端口
+	sshTimeout = 10 * time.Second // ssh 连接超时时间
+)
+
+var (
+	HostKeyAskMode    = "ask"    // 主机公钥处理方式 - 未知公钥询问用户，信任后记录到 known_hosts
+	HostKeyStrictMode = "strict" // 主机公钥处理方式 - 拒绝未知公钥
+)
+
+var hostKeyMutex sync.Mutex // 保证同一时间只有一个主机公钥询问
+
+// SetHostKeyCallback 为 ssh 公钥设置主机公钥校验方法
+//
+//   - 存储库源配置了 host_keys 时只接受指纹与之匹配的主机公钥，不再查询 known_hosts
+//   - 否则使用 known_hosts 校验，主机公钥变更时总是拒绝
+//   - 主机公钥未知时，ask 模式（默认）询问用户并将信任的公钥记录到 known_hosts，strict 模式拒绝
+//
+// 参数：
+//   - publicKeys: ssh 公钥
+//   - config: 配置项
+//
+// 返回：
+//   - 错误信息，ssh.host_key_mode 不是支持的值时返回错误
+func SetHostKeyCallback(publicKeys *ssh.PublicKeys, config *Config) error {
+	switch config.SSH.HostKeyMode {
+	case "", HostKeyAskMode, HostKeyStrictMode:
+	default:
+		return fmt.Errorf("Unsupported ssh.host_key_mode '%s', optional values are '%s' and '%s'", config.SSH.HostKeyMode, HostKeyAskMode, HostKeyStrictMode)
+	}
+
+	pinnedKeys := make(map[string][]string)
+	for _, source := range GetSources(config) {
+		if len(source.HostKeys) > 0 {
+			pinnedKeys[source.Url] = append(pinnedKeys[source.Url], source.HostKeys...)
+			if hostName := ResolveSSHHost(source.Url).HostName; hostName != source.Url { // ssh_config 中的主机别名
+				pinnedKeys[hostName] = append(pinnedKeys[hostName], source.HostKeys...)
+			}
+		}
+	}
+	strict := config.SSH.HostKeyMode == HostKeyStrictMode
+
+	publicKeys.HostKeyCallback = func(hostname string, remote net.Addr, key cssh.PublicKey) error {
+		host := hostname
+		if splitHost, _, err := net.SplitHostPort(hostname); err == nil {
+			host = splitHost
+		}
+		fingerprint := cssh.FingerprintSHA256(key)
+
+		// 固定的主机公钥指纹
+		if fingerprints, ok := pinnedKeys[host]; ok {
+			for _, pinnedFingerprint := range fingerprints {
+				if strings.TrimPrefix(pinnedFingerprint, "SHA256:") == strings.TrimPrefix(fingerprint, "SHA256:") {
+					return nil
+				}
+			}
+			return fmt.Errorf("Host key of %s (%s %s) does not match the pinned host_keys %s", host, key.Type(), fingerprint, strings.Join(fingerprints, ", "))
+		}
+
+		hostKeyMutex.Lock()
+		defer hostKeyMutex.Unlock()
+
+		// known_hosts
+		var err error
+		if files, filesErr := GetKnownHostsFiles(); filesErr == nil {
+			callback, callbackErr := knownhosts.New(files...)
+			if callbackErr != nil {
+				return callbackErr
+			}
+			if err = callback(hostname, remote, key); err == nil {
+				return nil
+			}
+			var keyErr *knownhosts.KeyError
+			if !errors.As(err, &keyErr) {
+				return err
+			}
+			if len(keyErr.Want) > 0 {
+				return fmt.Errorf("Host key of %s has changed to %s %s, which may be a man-in-the-middle attack (remove the old key with 'ssh-keygen -R %s' if the change is expected)", host, key.Type(), fingerprint, host)
+			}
+		}
+
+		// 未知的主机公钥
+		if strict {
+			return fmt.Errorf("Host key of %s (%s %s) is unknown and ssh.host_key_mode is strict (add it to known_hosts or pin it in host_keys)", host, key.Type(), fingerprint)
+		}
+		return trustHostKey(hostname, host, key)
+	}
+	return nil
+}
+
+// trustHostKey 询问用户是否信任未知的主机公钥，信任后将其记录到 known_hosts
+//
+// 参数：
+//   - hostname: 连接地址，格式为 '主机:端口'
+//   - host: 主机地址
+//   - key: 主机公钥
+//
+// 返回：
+//   - 错误信息，用户不信任时返回错误
+func trustHostKey(hostname, host string, key cssh.PublicKey) error {
+	// 询问期间暂停等待动画
+	if WaitSpinner.Active() {
+		WaitSpinner.Stop()
+		defer WaitSpinner.Start()
+	}
+
+	color.Printf("\nThe authenticity of host '%s' can't be established.\n%s key fingerprint is %s.\n", PrimaryText(host), key.Type(), NoticeText(cssh.FingerprintSHA256(key)))
+	trust, err := AreYouSure(QuestionText("Trust this host and record its key in known_hosts?"), false)
+	if err != nil {
+		return err
+	}
+	if !trust {
+		return fmt.Errorf("Host key of %s was not trusted", host)
+	}
+
+	knownHostsFile, err := userKnownHostsFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(knownHostsFile), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(knownHostsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.WriteString(knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key) + "\n")
+	return err
+}
+
+// userKnownHostsFile 获取记录新主机公钥的 known_hosts 文件
+//
+//   - 设置了环境变量 SSH_KNOWN_HOSTS 时使用其中的第一个文件，否则使用 ~/.ssh/known_hosts
+//
+// 返回：
+//   - known_hosts 文件路径
+//   - 错误信息
+func userKnownHostsFile() (string, error) {
+	if files := filepath.SplitList(os.Getenv("SSH_KNOWN_HOSTS")); len(files) > 0 {
+		return files[0], nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".ssh", "known_hosts"), nil
+}
+
+// GetKnownHostsFiles 获取 go-git 使用的 known_hosts 文件
+//
+//   - 与 go-git 的规则一致：优先使用环境变量 SSH_KNOWN_HOSTS，否则使用 ~/.ssh/known_hosts 和 /etc/ssh/ssh_known_hosts
+//
+// 参数：
+//   - 无
+//
+// 返回：
+//   - 存在的 known_hosts 文件
+//   - 错误信息
+func GetKnownHostsFiles() ([]string, error) {
+	files := filepath.SplitList(os.Getenv("SSH_KNOWN_HOSTS"))
+	if len(files) == 0 {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		files = []string{filepath.Join(homeDir, ".ssh", "known_hosts"), "/etc/ssh/ssh_known_hosts"}
+	}
+
+	var existFiles []string
+	for _, file := range files {
+		if FileExist(file) {
+			existFiles = append(existFiles, file)
+		}
+	}
+	if len(existFiles) == 0 {
+		return nil, fmt.Errorf("No known_hosts file found in %s", strings.Join(files, ", "))
+	}
+	return existFiles, nil
+}
+
+// IsHostKnown 检查 known_hosts 文件中是否有主机的公钥
+//
+// 参数：
+//   - host: 主机地址
+//
+// 返回：
+//   - 有主机公钥返回 true，否则返回 false
+//   - 错误信息
+func IsHostKnown(host string) (bool, error) {
+	files, err := GetKnownHostsFiles()
+	if err != nil {
+		return false, err
+	}
+	callback, err := knownhosts.New(files...)
+	if err != nil {
+		return false, err
+	}
+
+	// 用随机生成的公钥检查，主机已知时会返回带有期望公钥的 KeyError
+	rawKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return false, err
+	}
+	probeKey, err := cssh.NewPublicKey(rawKey)
+	if err != nil {
+		return false, err
+	}
+	sshHost := ResolveSSHHost(host)
+	err = callback(sshHost.Address(), &net.TCPAddr{IP: net.IPv4zero, Port: sshHost.Port}, probeKey)
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) {
+		return len(keyErr.Want) > 0, nil
+	}
+	return err == nil, err
+}
+
+// CheckSSHAuth 使用与 go-git 相同的配置（包括 ssh_config）连接主机，检查公钥是否被接受
+//
+// 参数：
+//   - host: 主机地址
+//   - publicKeys: ssh 公钥
+//
+// 返回：
+//   - 错误信息，连接和认证都成功时为 nil
+func CheckSSHAuth(host string, publicKeys *ssh.PublicKeys) error {
+	endpoint, auth, err := applySSHConfig(&transport.Endpoint{Protocol: "ssh", User: publicKeys.User, Host: host, Port: sshPort}, publicKeys)
+	if err != nil {
+		return err
+	}
+	clientConfig, err := auth.(ssh.AuthMethod).ClientConfig()
+	if err != nil {
+		return err
+	}
+	clientConfig.Timeout = sshTimeout
+
+	address := net.JoinHostPort(endpoint.Host, strconv.Itoa(endpoint.Port))
+	var dialer proxy.Dialer = &net.Dialer{Timeout: sshTimeout}
+	if endpoint.Proxy.URL != "" {
+		proxyUrl, err := endpoint.Proxy.FullURL()
+		if err != nil {
+			return err
+		}
+		if dialer, err = proxy.FromURL(proxyUrl, proxy.Direct); err != nil {
+			return err
+		}
+	}
+	conn, err := dialer.Dial("tcp", address)
+	if err != nil {
+		return err
+	}
+	clientConn, channels, requests, err := cssh.NewClientConn(conn, address, clientConfig)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	return cssh.NewClient(clientConn, channels, requests).Close()
+}
+
+// ClearPassword 清除内存中的密码，以增加安全性
 //
 // 参数：
 //   - password: 密码
-func clearPassword(password []byte) {
+func ClearPassword(password []byte) {
 	for i := range password {
 		password[i] = 0
 	}
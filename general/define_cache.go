@@ -0,0 +1,212 @@
+/*
+File: define_cache.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 09:12:36
+
+Description: 本地对象缓存
+
+- 缓存目录中保存每个存储库的裸镜像，路径格式为 <缓存目录>/<地址>/<用户名>/<存储库名>.git
+*/
+
+package general
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+var cacheRepoSuffix = ".git" // 缓存存储库目录后缀
+
+// GetCacheRepoPath 获取远端存储库在缓存目录中的裸镜像路径
+//
+// 参数：
+//   - cachePath: 缓存目录
+//   - URL: 远端存储库地址（仅包括主地址，例如：github.com）
+//   - username: 远端存储库用户名
+//   - repoName: 远端存储库名称
+//
+// 返回：
+//   - 裸镜像路径
+func GetCacheRepoPath(cachePath, URL, username, repoName string) string {
+	return filepath.Join(cachePath, URL, username, repoName+cacheRepoSuffix)
+}
+
+// UpdateCacheRepo 更新缓存中的裸镜像，不存在则创建
+//
+// 参数：
+//   - cacheRepoPath: 裸镜像路径
+//   - URL: 远端存储库地址（仅包括主地址，例如：github.com）
+//   - username: 远端存储库用户名
+//   - repoName: 远端存储库名称
+//   - publicKeys: ssh 公钥
+//
+// 返回：
+//   - 错误信息
+func UpdateCacheRepo(cacheRepoPath, URL, username, repoName string, publicKeys *ssh.PublicKeys) error {
+	if FileExist(cacheRepoPath) {
+		isRepo, repo, _ := IsLocalRepo(cacheRepoPath)
+		if isRepo {
+			_, _, err := FetchMirrorRepo(repo, publicKeys)
+			if err != nil && err != git.NoErrAlreadyUpToDate {
+				return err
+			}
+			return nil
+		}
+		// 不是存储库的残留目录，删除后重新创建
+		if err := DeleteFile(cacheRepoPath); err != nil {
+			return err
+		}
+	}
+
+	_, err := MirrorRepoViaSSH(cacheRepoPath, URL, username, repoName, publicKeys)
+	return err
+}
+
+// CloneRepoViaCache 先更新缓存中的裸镜像，再以共享对象的方式从裸镜像 Clone 到本地，最后将 origin 指回远端存储库
+//
+//   - 等同于 'git clone --reference'，对象不复制，通过 .git/objects/info/alternates 引用裸镜像中的对象，因此被引用的裸镜像不能删除
+//   - 缓存刚从远端更新过，远程分支直接取自裸镜像，无需再次从远端拉取
+//   - 子模块在 origin 指回远端存储库后再初始化，保证相对路径的子模块地址能正确解析
+//
+// 参数：
+//   - repoPath: 本地存储库路径
+//   - cacheRepoPath: 裸镜像路径
+//   - URL: 远端存储库地址（仅包括主地址，例如：github.com）
+//   - username: 远端存储库用户名
+//   - repoName: 远端存储库名称
+//   - publicKeys: ssh 公钥
+//
+// 返回：
+//   - 本地存储库对象
+//   - 错误信息
+func CloneRepoViaCache(repoPath, cacheRepoPath, URL, username, repoName string, publicKeys *ssh.PublicKeys) (*git.Repository, error) {
+	// 更新缓存
+	if err := UpdateCacheRepo(cacheRepoPath, URL, username, repoName, publicKeys); err != nil {
+		return nil, err
+	}
+
+	// 从缓存 Clone，通过 alternates 共享缓存中的对象
+	absCacheRepoPath, err := filepath.Abs(cacheRepoPath)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := git.PlainClone(repoPath, false, &git.CloneOptions{
+		URL:      absCacheRepoPath,
+		Shared:   true,
+		Progress: io.Discard,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 将 origin 指回远端存储库
+	repoConfig, err := repo.Config()
+	if err != nil {
+		return repo, err
+	}
+	repoConfig.Remotes[remoteName].URLs = []string{BuildRepoUrl(URL, username, repoName)}
+	if err := repo.SetConfig(repoConfig); err != nil {
+		return repo, err
+	}
+
+	// 初始化并更新子模块
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return repo, err
+	}
+	submodules, err := worktree.Submodules()
+	if err != nil {
+		return repo, err
+	}
+	err = submodules.Update(&git.SubmoduleUpdateOptions{
+		Init:              true,
+		RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
+		Auth:              publicKeys,
+	})
+
+	return repo, err
+}
+
+// GetRepoAlternates 获取本地存储库通过 alternates 引用的对象目录
+//
+// 参数：
+//   - repoPath: 本地存储库路径
+//
+// 返回：
+//   - 对象目录的绝对路径，没有 alternates 时为空
+func GetRepoAlternates(repoPath string) []string {
+	var alternates []string
+	for _, alternatesFile := range []string{
+		filepath.Join(repoPath, ".git", "objects", "info", "alternates"), // 带工作树的存储库
+		filepath.Join(repoPath, "objects", "info", "alternates"),         // 裸存储库
+	} {
+		data, err := os.ReadFile(alternatesFile)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if !filepath.IsAbs(line) { // 相对路径基于 objects 目录
+				line = filepath.Join(filepath.Dir(filepath.Dir(alternatesFile)), line)
+			}
+			alternates = append(alternates, filepath.Clean(line))
+		}
+	}
+	return alternates
+}
+
+// ListCacheRepos 列出缓存目录中的所有裸镜像
+//
+// 参数：
+//   - cachePath: 缓存目录
+//
+// 返回：
+//   - 裸镜像路径
+//   - 错误信息
+func ListCacheRepos(cachePath string) ([]string, error) {
+	var cacheRepos []string
+
+	if !FileExist(cachePath) {
+		return cacheRepos, nil
+	}
+
+	err := filepath.WalkDir(cachePath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && path != cachePath && strings.HasSuffix(entry.Name(), cacheRepoSuffix) {
+			cacheRepos = append(cacheRepos, path)
+			return filepath.SkipDir // 不再深入裸镜像内部
+		}
+		return nil
+	})
+
+	return cacheRepos, err
+}
+
+// DeleteEmptyParents 自下而上删除空的父目录，直到 root 为止（不包括 root）
+//
+// 参数：
+//   - path: 起始路径（已被删除的文件或文件夹）
+//   - root: 终止路径
+func DeleteEmptyParents(path, root string) {
+	root = filepath.Clean(root)
+	for dir := filepath.Dir(path); dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		if !FolderEmpty(dir) {
+			return
+		}
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+	}
+}
@@ -10,6 +10,7 @@ Description: 单位和格式数据转换
 package general
 
 import (
+	"sort"
 	"strings"
 )
 
@@ -27,3 +28,19 @@ func UpperFirstChar(str string) string {
 
 	return strings.ToUpper(str[:1]) + str[1:]
 }
+
+// sortedKeys 获取 map 的所有键并排序
+//
+// 参数：
+//   - m: 键为字符串的 map
+//
+// 返回：
+//   - 排序后的键
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,715 @@
+/*
+File: define_credential.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 23:05:12
+
+Description: 加密的凭据存储
+
+- 保存存储库源的访问令牌和私钥密码，文件内容使用主密码经 scrypt 派生的密钥以 XChaCha20-Poly1305 加密
+- 主密码在每次运行中最多询问一次，使用后立即从内存中清除，只缓存其派生的密钥，解密后的凭据用完即清除
+- 访问令牌和密码以字节切片传递，调用方使用后应调用 ClearPassword 清除
+*/
+
+package general
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/gookit/color"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	credentialVersion = 1     // 凭据文件格式版本
+	scryptN           = 32768 // scrypt 参数 N
+	scryptR           = 8     // scrypt 参数 r
+	scryptP           = 1     // scrypt 参数 p
+	scryptSaltLength  = 16    // scrypt 盐长度
+)
+
+var ErrWrongPassphrase = errors.New("Wrong master passphrase") // 主密码错误
+
+var (
+	credentialMutex     sync.Mutex                      // 保护已解锁的派生密钥和存储库源映射
+	credentialKey       []byte                          // 主密码派生的密钥，解锁后缓存以免重复询问主密码，未解锁时为 nil
+	credentialSalt      []byte                          // 派生 credentialKey 使用的盐，凭据文件重写后不再匹配
+	credentialUnlockErr error                           // 解锁失败的原因，本次运行中不再询问主密码
+	credentialSources   = make(map[string]SourceConfig) // 存储库源地址到存储库源的映射
+)
+
+// Secret 访问令牌或私钥密码，以字节切片保存以便使用后清除，在凭据文件中的格式与 JSON 字符串相同
+type Secret []byte
+
+// UnmarshalJSON 将 JSON 字符串直接解码为字节切片，不经过 Go 字符串
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	secret, err := decodeJSONString(data)
+	if err != nil {
+		return err
+	}
+	*s = secret
+	return nil
+}
+
+// CredentialStore 凭据
+type CredentialStore struct {
+	Tokens      map[string]Secret `json:"tokens,omitempty"`      // 存储库源名称到访问令牌的映射
+	Passphrases map[string]Secret `json:"passphrases,omitempty"` // 私钥文件到密码的映射
+}
+
+// Clear 清除凭据中的所有访问令牌和密码
+func (store *CredentialStore) Clear() {
+	if store == nil {
+		return
+	}
+	for _, secrets := range []map[string]Secret{store.Tokens, store.Passphrases} {
+		for _, secret := range secrets {
+			ClearPassword(secret)
+		}
+	}
+}
+
+// credentialEnvelope 凭据文件的内容
+type credentialEnvelope struct {
+	Version    int    `json:"version"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// ReadCredentialStore 使用主密码读取凭据文件
+//
+// 参数：
+//   - filePath: 凭据文件路径
+//   - passphrase: 主密码
+//
+// 返回：
+//   - 凭据，使用后应调用 Clear 清除
+//   - 错误信息，主密码错误时为 ErrWrongPassphrase
+func ReadCredentialStore(filePath string, passphrase []byte) (*CredentialStore, error) {
+	envelope, err := readCredentialEnvelope(filePath)
+	if err != nil {
+		return nil, err
+	}
+	key, err := deriveCredentialKey(passphrase, envelope)
+	if err != nil {
+		return nil, err
+	}
+	defer ClearPassword(key)
+	return openCredentialEnvelope(envelope, key)
+}
+
+// WriteCredentialStore 使用主密码加密并写入凭据文件
+//
+// 参数：
+//   - filePath: 凭据文件路径
+//   - passphrase: 主密码
+//   - store: 凭据
+//
+// 返回：
+//   - 错误信息
+func WriteCredentialStore(filePath string, passphrase []byte, store *CredentialStore) error {
+	envelope, key, err := newCredentialEnvelope(passphrase)
+	if err != nil {
+		return err
+	}
+	defer ClearPassword(key)
+	return sealCredentialStore(filePath, envelope, key, store)
+}
+
+// newCredentialEnvelope 为新的凭据文件生成盐并从主密码派生密钥
+//
+// 参数：
+//   - passphrase: 主密码
+//
+// 返回：
+//   - 凭据文件的内容，尚未包含密文
+//   - 派生的密钥，使用后应调用 ClearPassword 清除
+//   - 错误信息
+func newCredentialEnvelope(passphrase []byte) (*credentialEnvelope, []byte, error) {
+	envelope := &credentialEnvelope{
+		Version: credentialVersion,
+		N:       scryptN,
+		R:       scryptR,
+		P:       scryptP,
+		Salt:    make([]byte, scryptSaltLength),
+	}
+	if _, err := rand.Read(envelope.Salt); err != nil {
+		return nil, nil, err
+	}
+	key, err := deriveCredentialKey(passphrase, envelope)
+	if err != nil {
+		return nil, nil, err
+	}
+	return envelope, key, nil
+}
+
+// sealCredentialStore 使用派生的密钥和新的随机数加密凭据并写入凭据文件
+//
+// 参数：
+//   - filePath: 凭据文件路径
+//   - envelope: 凭据文件的内容，使用其中的 scrypt 参数和盐
+//   - key: 派生的密钥
+//   - store: 凭据
+//
+// 返回：
+//   - 错误信息
+func sealCredentialStore(filePath string, envelope *credentialEnvelope, key []byte, store *CredentialStore) error {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return err
+	}
+	envelope.Nonce = make([]byte, chacha20poly1305.NonceSizeX)
+	if _, err := rand.Read(envelope.Nonce); err != nil {
+		return err
+	}
+	plaintext := encodeCredentialStore(store)
+	envelope.Ciphertext = aead.Seal(nil, envelope.Nonce, plaintext, nil)
+	ClearPassword(plaintext)
+
+	data, err := json.MarshalIndent(envelope, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+		return err
+	}
+	// 先写入临时文件再替换，避免写入中断损坏凭据文件
+	tempFile := filePath + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tempFile, filePath)
+}
+
+// readCredentialEnvelope 读取凭据文件中的加密内容
+//
+// 参数：
+//   - filePath: 凭据文件路径
+//
+// 返回：
+//   - 凭据文件的内容
+//   - 错误信息
+func readCredentialEnvelope(filePath string) (*credentialEnvelope, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var envelope credentialEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("Invalid credential file %s: %s", filePath, err)
+	}
+	if envelope.Version != credentialVersion {
+		return nil, fmt.Errorf("Unsupported credential file version %d", envelope.Version)
+	}
+	return &envelope, nil
+}
+
+// deriveCredentialKey 使用 scrypt 从主密码派生密钥
+//
+// 参数：
+//   - passphrase: 主密码
+//   - envelope: 凭据文件的内容，使用其中的 scrypt 参数和盐
+//
+// 返回：
+//   - 密钥，使用后应调用 ClearPassword 清除
+//   - 错误信息
+func deriveCredentialKey(passphrase []byte, envelope *credentialEnvelope) ([]byte, error) {
+	return scrypt.Key(passphrase, envelope.Salt, envelope.N, envelope.R, envelope.P, chacha20poly1305.KeySize)
+}
+
+// openCredentialEnvelope 使用派生的密钥解密凭据
+//
+// 参数：
+//   - envelope: 凭据文件的内容
+//   - key: 派生的密钥
+//
+// 返回：
+//   - 凭据，使用后应调用 Clear 清除
+//   - 错误信息，密钥不正确时为 ErrWrongPassphrase
+func openCredentialEnvelope(envelope *credentialEnvelope, key []byte) (*CredentialStore, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, envelope.Nonce, envelope.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	defer ClearPassword(plaintext)
+
+	store := &CredentialStore{}
+	if err := json.Unmarshal(plaintext, store); err != nil {
+		store.Clear()
+		return nil, err
+	}
+	return store, nil
+}
+
+// UnlockCredentialStore 解锁并读取凭据文件
+//
+//   - 凭据文件不存在时返回空的凭据，不询问主密码
+//   - 只缓存主密码派生的密钥，不缓存解密后的凭据，因此每次运行最多询问一次主密码
+//   - 解锁失败（例如主密码错误）的结果同样会被缓存，本次运行中不再询问
+//
+// 返回：
+//   - 凭据，使用后应调用 Clear 清除
+//   - 错误信息
+func UnlockCredentialStore() (*CredentialStore, error) {
+	credentialMutex.Lock()
+	defer credentialMutex.Unlock()
+
+	if !FileExist(CredentialFile) {
+		return &CredentialStore{}, nil
+	}
+	if credentialUnlockErr != nil {
+		return nil, credentialUnlockErr
+	}
+	envelope, err := readCredentialEnvelope(CredentialFile)
+	if err != nil {
+		return nil, err
+	}
+	if credentialKey != nil && bytes.Equal(credentialSalt, envelope.Salt) {
+		return openCredentialEnvelope(envelope, credentialKey)
+	}
+
+	// 询问密码期间暂停等待动画
+	if WaitSpinner.Active() {
+		WaitSpinner.Stop()
+		defer WaitSpinner.Start()
+	}
+	store, key, err := openCredentialStore(CredentialFile, envelope)
+	if err != nil {
+		credentialUnlockErr = fmt.Errorf("Unable to unlock %s: %s", CredentialFile, err)
+		return nil, credentialUnlockErr
+	}
+	cacheCredentialKey(key, envelope.Salt)
+	return store, nil
+}
+
+// cacheCredentialKey 缓存派生的密钥，替换并清除之前缓存的密钥，调用方需持有 credentialMutex
+//
+// 参数：
+//   - key: 派生的密钥
+//   - salt: 派生密钥使用的盐
+func cacheCredentialKey(key, salt []byte) {
+	ClearPassword(credentialKey)
+	credentialKey, credentialSalt = key, salt
+	credentialUnlockErr = nil
+}
+
+// openCredentialStore 询问主密码并读取凭据文件，最多尝试 3 次
+//
+// 参数：
+//   - filePath: 凭据文件路径
+//   - envelope: 凭据文件的内容
+//
+// 返回：
+//   - 凭据，使用后应调用 Clear 清除
+//   - 主密码派生的密钥，使用后应调用 ClearPassword 清除
+//   - 错误信息
+func openCredentialStore(filePath string, envelope *credentialEnvelope) (*CredentialStore, []byte, error) {
+	maxAttempts := 3 // 最大尝试次数
+	for attempts := 0; attempts < maxAttempts; attempts++ {
+		passphrase, err := ReadPassword(color.Sprintf("Enter master passphrase for '%s' (%s/%s): ", PrimaryText(filePath), WarnText(attempts+1), NoticeText(maxAttempts)))
+		if err != nil {
+			return nil, nil, err
+		}
+		key, err := deriveCredentialKey(passphrase, envelope)
+		ClearPassword(passphrase)
+		if err != nil {
+			return nil, nil, err
+		}
+		store, err := openCredentialEnvelope(envelope, key)
+		if err == nil {
+			return store, key, nil
+		}
+		ClearPassword(key)
+		if err != ErrWrongPassphrase {
+			return nil, nil, err
+		}
+	}
+	return nil, nil, ErrWrongPassphrase
+}
+
+// UpdateCredentialStore 修改凭据文件
+//
+//   - 凭据文件存在时使用已缓存的密钥，没有时询问主密码，修改后以原来的密钥和新的随机数重新加密
+//   - 凭据文件不存在时询问两次新的主密码并创建
+//
+// 参数：
+//   - update: 修改凭据的函数，返回错误时不写入凭据文件
+//
+// 返回：
+//   - 错误信息
+func UpdateCredentialStore(update func(store *CredentialStore) error) error {
+	credentialMutex.Lock()
+	defer credentialMutex.Unlock()
+
+	var (
+		envelope *credentialEnvelope
+		store    *CredentialStore
+		key      []byte
+		err      error
+	)
+	if FileExist(CredentialFile) {
+		if envelope, err = readCredentialEnvelope(CredentialFile); err != nil {
+			return err
+		}
+		if credentialKey != nil && bytes.Equal(credentialSalt, envelope.Salt) {
+			key = bytes.Clone(credentialKey)
+			store, err = openCredentialEnvelope(envelope, key)
+		} else {
+			store, key, err = openCredentialStore(CredentialFile, envelope)
+		}
+		if err != nil {
+			ClearPassword(key)
+			return err
+		}
+	} else {
+		color.Printf("%s %s\n", NoticeText("Creating credential file"), PrimaryText(CredentialFile))
+		passphrase, err := ReadPassword("Enter new master passphrase: ")
+		if err != nil {
+			return err
+		}
+		confirmPassphrase, err := ReadPassword("Enter same master passphrase again: ")
+		if err != nil {
+			ClearPassword(passphrase)
+			return err
+		}
+		same := bytes.Equal(passphrase, confirmPassphrase)
+		ClearPassword(confirmPassphrase)
+		if len(passphrase) == 0 || !same {
+			ClearPassword(passphrase)
+			return fmt.Errorf("Master passphrases are empty or do not match")
+		}
+		envelope, key, err = newCredentialEnvelope(passphrase)
+		ClearPassword(passphrase)
+		if err != nil {
+			return err
+		}
+		store = &CredentialStore{}
+	}
+	defer store.Clear()
+
+	if store.Tokens == nil {
+		store.Tokens = make(map[string]Secret)
+	}
+	if store.Passphrases == nil {
+		store.Passphrases = make(map[string]Secret)
+	}
+	if err := update(store); err != nil {
+		ClearPassword(key)
+		return err
+	}
+	if err := sealCredentialStore(CredentialFile, envelope, key, store); err != nil {
+		ClearPassword(key)
+		return err
+	}
+	cacheCredentialKey(key, envelope.Salt)
+	return nil
+}
+
+// LookupToken 从凭据文件中查找存储库源的访问令牌
+//
+// 参数：
+//   - sourceName: 存储库源名称
+//
+// 返回：
+//   - 访问令牌，没有时为 nil，使用后应调用 ClearPassword 清除
+//   - 错误信息，凭据文件无法解锁时返回
+func LookupToken(sourceName string) ([]byte, error) {
+	if sourceName == "" {
+		return nil, nil
+	}
+	return lookupSecret(func(store *CredentialStore) Secret { return store.Tokens[sourceName] })
+}
+
+// LookupPassphrase 从凭据文件中查找私钥的密码
+//
+// 参数：
+//   - keyFile: 私钥文件路径
+//
+// 返回：
+//   - 密码，没有时为 nil，使用后应调用 ClearPassword 清除
+//   - 错误信息，凭据文件无法解锁时返回
+func LookupPassphrase(keyFile string) ([]byte, error) {
+	return lookupSecret(func(store *CredentialStore) Secret { return store.Passphrases[keyFile] })
+}
+
+// lookupSecret 从凭据文件中复制一项凭据，其余凭据随即清除
+//
+// 参数：
+//   - find: 从凭据中取出所需凭据的函数
+//
+// 返回：
+//   - 凭据的副本，没有时为 nil
+//   - 错误信息
+func lookupSecret(find func(store *CredentialStore) Secret) ([]byte, error) {
+	if !FileExist(CredentialFile) {
+		return nil, nil
+	}
+	store, err := UnlockCredentialStore()
+	if err != nil {
+		return nil, err
+	}
+	defer store.Clear()
+	if secret := find(store); len(secret) > 0 {
+		return bytes.Clone(secret), nil
+	}
+	return nil, nil
+}
+
+// GetSourceToken 获取存储库源的访问令牌
+//
+//   - 配置文件中的 token 优先，其次是凭据文件
+//
+// 参数：
+//   - source: 存储库源配置
+//
+// 返回：
+//   - 访问令牌，没有时为 nil，使用后应调用 ClearPassword 清除
+//   - 错误信息，凭据文件无法解锁时返回
+func GetSourceToken(source SourceConfig) ([]byte, error) {
+	if source.Token != "" {
+		return []byte(source.Token), nil
+	}
+	return LookupToken(source.Name)
+}
+
+// SetCredentialSources 注册存储库源，供 HTTPS 传输按主机查找访问令牌
+//
+// 参数：
+//   - config: 配置项
+func SetCredentialSources(config *Config) {
+	credentialMutex.Lock()
+	defer credentialMutex.Unlock()
+
+	credentialSources = make(map[string]SourceConfig)
+	for _, source := range GetSources(config) {
+		credentialSources[source.Url] = source
+	}
+}
+
+// httpAuthTransport 使用访问令牌认证 HTTPS 传输
+//
+//   - 调用方传入的不是 HTTP 认证方式（例如 ssh 公钥）时，改用主机所属存储库源的访问令牌，没有令牌时匿名访问
+type httpAuthTransport struct {
+	transport transport.Transport
+}
+
+func (t *httpAuthTransport) NewUploadPackSession(endpoint *transport.Endpoint, auth transport.AuthMethod) (transport.UploadPackSession, error) {
+	return t.transport.NewUploadPackSession(endpoint, httpAuth(endpoint, auth))
+}
+
+func (t *httpAuthTransport) NewReceivePackSession(endpoint *transport.Endpoint, auth transport.AuthMethod) (transport.ReceivePackSession, error) {
+	return t.transport.NewReceivePackSession(endpoint, httpAuth(endpoint, auth))
+}
+
+// httpAuth 获取 HTTPS 传输使用的认证方式
+//
+// 参数：
+//   - endpoint: 存储库地址
+//   - auth: 调用方传入的认证方式
+//
+// 返回：
+//   - HTTP 认证方式，匿名访问时为 nil
+func httpAuth(endpoint *transport.Endpoint, auth transport.AuthMethod) transport.AuthMethod {
+	if _, ok := auth.(githttp.AuthMethod); ok {
+		return auth
+	}
+
+	credentialMutex.Lock()
+	source, ok := credentialSources[endpoint.Host]
+	credentialMutex.Unlock()
+	if !ok {
+		return nil
+	}
+	token, err := GetSourceToken(source)
+	if err != nil {
+		color.Printf("%s %s\n", WarnText("Access", endpoint.Host, "anonymously:"), SecondaryText(err))
+		return nil
+	}
+	if token == nil {
+		return nil
+	}
+	defer ClearPassword(token)
+	return &githttp.BasicAuth{Username: source.Username, Password: string(token)}
+}
+
+// encodeCredentialStore 将凭据编码为 JSON，不经过 encoding/json 的缓冲区，以免凭据的副本残留在内存中
+//
+// 参数：
+//   - store: 凭据
+//
+// 返回：
+//   - JSON 格式的凭据，使用后应调用 ClearPassword 清除
+func encodeCredentialStore(store *CredentialStore) []byte {
+	data := []byte{'{'}
+	for index, group := range []struct {
+		name    string
+		secrets map[string]Secret
+	}{{"tokens", store.Tokens}, {"passphrases", store.Passphrases}} {
+		if index > 0 {
+			data = append(data, ',')
+		}
+		data = append(appendJSONString(data, []byte(group.name)), ':', '{')
+		for keyIndex, key := range sortedKeys(group.secrets) {
+			if keyIndex > 0 {
+				data = append(data, ',')
+			}
+			data = append(appendJSONString(data, []byte(key)), ':')
+			data = appendJSONString(data, group.secrets[key])
+		}
+		data = append(data, '}')
+	}
+	return append(data, '}')
+}
+
+// appendJSONString 将字节切片编码为 JSON 字符串追加到 data
+//
+//   - 追加前容量不足时会清除旧的底层数组
+//
+// 参数：
+//   - data: 已编码的内容
+//   - value: 待编码的字节切片
+//
+// 返回：
+//   - 追加后的内容
+func appendJSONString(data, value []byte) []byte {
+	grow := func(extra int) {
+		if len(data)+extra > cap(data) {
+			grown := make([]byte, len(data), 2*cap(data)+extra)
+			copy(grown, data)
+			ClearPassword(data)
+			data = grown
+		}
+	}
+	grow(len(value) + 2)
+	data = append(data, '"')
+	for _, char := range value {
+		switch {
+		case char == '"' || char == '\\':
+			grow(2)
+			data = append(data, '\\', char)
+		case char < 0x20:
+			grow(6)
+			data = append(data, '\\', 'u', '0', '0', "0123456789abcdef"[char>>4], "0123456789abcdef"[char&0xf])
+		default:
+			grow(1)
+			data = append(data, char)
+		}
+	}
+	grow(1)
+	return append(data, '"')
+}
+
+// decodeJSONString 将 JSON 字符串解码为字节切片
+//
+// 参数：
+//   - data: 带引号的 JSON 字符串
+//
+// 返回：
+//   - 解码后的字节切片
+//   - 错误信息
+func decodeJSONString(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return nil, errors.New("Credential is not a string")
+	}
+	data = data[1 : len(data)-1]
+	value := make([]byte, 0, len(data))
+	for index := 0; index < len(data); index++ {
+		if data[index] != '\\' {
+			value = append(value, data[index])
+			continue
+		}
+		index++
+		if index >= len(data) {
+			ClearPassword(value)
+			return nil, errors.New("Invalid escape in credential")
+		}
+		switch data[index] {
+		case '"', '\\', '/':
+			value = append(value, data[index])
+		case 'b':
+			value = append(value, '\b')
+		case 'f':
+			value = append(value, '\f')
+		case 'n':
+			value = append(value, '\n')
+		case 'r':
+			value = append(value, '\r')
+		case 't':
+			value = append(value, '\t')
+		case 'u':
+			char, size := decodeJSONUnicode(data[index+1:])
+			if size == 0 {
+				ClearPassword(value)
+				return nil, errors.New("Invalid unicode escape in credential")
+			}
+			value = utf8.AppendRune(value, char)
+			index += size
+		default:
+			ClearPassword(value)
+			return nil, errors.New("Invalid escape in credential")
+		}
+	}
+	return value, nil
+}
+
+// decodeJSONUnicode 解码 '\u' 之后的 Unicode 转义，支持代理对
+//
+// 参数：
+//   - data: '\u' 之后的内容
+//
+// 返回：
+//   - 字符
+//   - 消耗的字节数，无效时为 0
+func decodeJSONUnicode(data []byte) (rune, int) {
+	hexValue := func(data []byte) rune {
+		if len(data) < 4 {
+			return -1
+		}
+		var value rune
+		for _, char := range data[:4] {
+			switch {
+			case '0' <= char && char <= '9':
+				value = value<<4 | rune(char-'0')
+			case 'a' <= char && char <= 'f':
+				value = value<<4 | rune(char-'a'+10)
+			case 'A' <= char && char <= 'F':
+				value = value<<4 | rune(char-'A'+10)
+			default:
+				return -1
+			}
+		}
+		return value
+	}
+	char := hexValue(data)
+	if char < 0 {
+		return 0, 0
+	}
+	if utf16.IsSurrogate(char) && len(data) >= 10 && data[4] == '\\' && data[5] == 'u' {
+		if low := hexValue(data[6:]); low >= 0 {
+			if combined := utf16.DecodeRune(char, low); combined != utf8.RuneError {
+				return combined, 10
+			}
+		}
+	}
+	return char, 4
+}
@@ -0,0 +1,66 @@
+/*
+File: define_credential_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 21:36:18
+
+Description: 测试凭据文件的加密读写
+*/
+
+package general
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestCredentialStoreRoundTrip 写入后使用同一主密码读回相同的凭据，需要转义的字符原样保留
+func TestCredentialStoreRoundTrip(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "credentials")
+	tokens := map[string]string{
+		"github": "ghp_plain",
+		"gitea":  "quote\" backslash\\ newline\n tab\t unicode 令牌 \x01",
+	}
+	store := &CredentialStore{Tokens: map[string]Secret{}, Passphrases: map[string]Secret{"/home/ops/.ssh/id_ed25519": Secret("key pass")}}
+	for name, token := range tokens {
+		store.Tokens[name] = Secret(token)
+	}
+	if err := WriteCredentialStore(filePath, []byte("master"), store); err != nil {
+		t.Fatal(err)
+	}
+
+	readStore, err := ReadCredentialStore(filePath, []byte("master"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer readStore.Clear()
+	if len(readStore.Tokens) != len(tokens) {
+		t.Fatalf("tokens = %d, want %d", len(readStore.Tokens), len(tokens))
+	}
+	for name, token := range tokens {
+		if string(readStore.Tokens[name]) != token {
+			t.Errorf("token %s = %q, want %q", name, readStore.Tokens[name], token)
+		}
+	}
+	if string(readStore.Passphrases["/home/ops/.ssh/id_ed25519"]) != "key pass" {
+		t.Errorf("passphrase = %q, want %q", readStore.Passphrases["/home/ops/.ssh/id_ed25519"], "key pass")
+	}
+
+	// 错误的主密码
+	if _, err := ReadCredentialStore(filePath, []byte("wrong")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("err = %v, want %v", err, ErrWrongPassphrase)
+	}
+}
+
+// TestCredentialStoreClear 清除后凭据内容被覆盖
+func TestCredentialStoreClear(t *testing.T) {
+	token := []byte("secret")
+	store := &CredentialStore{Tokens: map[string]Secret{"github": Secret(token)}}
+	store.Clear()
+	for _, b := range token {
+		if b != 0 {
+			t.Fatalf("token = %q, want cleared", token)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+/*
+File: define_drift.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 16:05:37
+
+Description: 对比多个存储库源之间的引用差异
+
+- 引用通过 ls-remote 获取，不需要本地存储库
+*/
+
+package general
+
+import (
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// RefDrift 一个引用在各存储库源之间的差异
+type RefDrift struct {
+	Ref    plumbing.ReferenceName // 引用名
+	Hashes map[string]string      // 存储库源名称到提交哈希的映射，缺少该引用的存储库源不在其中
+}
+
+// ListRemoteRefs 使用 ls-remote 的方式获取远端存储库的分支和标签
+//
+// 参数：
+//   - URL: 远端存储库地址（仅包括主地址，例如：github.com）
+//   - username: 远端存储库用户名
+//   - repoName: 远端存储库名称
+//   - publicKeys: ssh 公钥
+//
+// 返回：
+//   - 引用名到提交哈希的映射
+//   - 错误信息
+func ListRemoteRefs(URL, username, repoName string, publicKeys *ssh.PublicKeys) (map[plumbing.ReferenceName]string, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: remoteName,
+		URLs: []string{BuildRepoUrl(URL, username, repoName)},
+	})
+	references, err := remote.List(&git.ListOptions{
+		Auth:          publicKeys,
+		PeelingOption: git.IgnorePeeled,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make(map[plumbing.ReferenceName]string)
+	for _, reference := range references {
+		if reference.Type() != plumbing.HashReference {
+			continue
+		}
+		if reference.Name().IsBranch() || reference.Name().IsTag() {
+			refs[reference.Name()] = reference.Hash().String()
+		}
+	}
+	return refs, nil
+}
+
+// CompareRemoteRefs 对比各存储库源的引用，找出缺失或指向不同提交的引用
+//
+// 参数：
+//   - sourceRefs: 存储库源名称到引用的映射
+//
+// 返回：
+//   - 存在差异的引用，按引用名排序
+func CompareRemoteRefs(sourceRefs map[string]map[plumbing.ReferenceName]string) []RefDrift {
+	// 所有存储库源的引用名并集
+	refNames := make(map[plumbing.ReferenceName]bool)
+	for _, refs := range sourceRefs {
+		for refName := range refs {
+			refNames[refName] = true
+		}
+	}
+
+	var drifts []RefDrift
+	for refName := range refNames {
+		drift := RefDrift{Ref: refName, Hashes: make(map[string]string)}
+		for source, refs := range sourceRefs {
+			if hash, ok := refs[refName]; ok {
+				drift.Hashes[source] = hash
+			}
+		}
+		if len(drift.Hashes) == len(sourceRefs) && !drift.Diverged() {
+			continue
+		}
+		drifts = append(drifts, drift)
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].Ref < drifts[j].Ref
+	})
+
+	return drifts
+}
+
+// Diverged 判断引用在拥有它的存储库源之间是否指向不同提交
+//
+// 返回：
+//   - 指向不同提交返回 true，否则返回 false
+func (d RefDrift) Diverged() bool {
+	hash := ""
+	for _, sourceHash := range d.Hashes {
+		if hash != "" && sourceHash != hash {
+			return true
+		}
+		hash = sourceHash
+	}
+	return false
+}
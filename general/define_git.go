@@ -15,7 +15,9 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/go-git/go-git/v5"
@@ -41,7 +43,7 @@ var remoteName = "origin" // 远程名称
 //   - 本地存储库对象
 //   - 错误信息
 func CloneRepoViaSSH(repoPath, URL, username, repoName string, publicKeys *ssh.PublicKeys) (*git.Repository, error) {
-	repoUrl := "git" + "@" + URL + ":" + username + "/" + repoName + ".git"
+	repoUrl := BuildRepoUrl(URL, username, repoName)
 	repo, err := git.PlainClone(repoPath, false, &git.CloneOptions{
 		URL:               repoUrl,
 		Auth:              publicKeys,
@@ -52,6 +54,104 @@ func CloneRepoViaSSH(repoPath, URL, username, repoName string, publicKeys *ssh.P
 	return repo, err
 }
 
+// MirrorRepoViaSSH 使用 SSH 协议将远端存储库以裸镜像的形式克隆到本地
+//
+//   - 等同于 'git clone --mirror'，不创建工作树，也不处理子模块
+//
+// 参数：
+//   - repoPath: 本地存储库路径
+//   - URL: 远端存储库地址（仅包括主地址，例如：github.com）
+//   - username: 远端存储库用户名
+//   - repoName: 远端存储库名称
+//   - publicKeys: ssh 公钥
+//
+// 返回：
+//   - 本地存储库对象
+//   - 错误信息
+func MirrorRepoViaSSH(repoPath, URL, username, repoName string, publicKeys *ssh.PublicKeys) (*git.Repository, error) {
+	repoUrl := BuildRepoUrl(URL, username, repoName)
+	repo, err := git.PlainClone(repoPath, true, &git.CloneOptions{
+		URL:      repoUrl,
+		Auth:     publicKeys,
+		Mirror:   true,
+		Progress: io.Discard,
+	})
+
+	return repo, err
+}
+
+// FetchMirrorRepo 拉取远端存储库的所有引用到本地裸镜像存储库，并删除远端已不存在的引用
+//
+//   - 等同于 'git remote update --prune'
+//
+// 参数：
+//   - repo: 本地裸镜像存储库对象
+//   - publicKeys: ssh 公钥
+//
+// 返回：
+//   - 拉取前 HEAD 指向的 Commit
+//   - 拉取后 HEAD 指向的 Commit
+//   - 错误信息
+func FetchMirrorRepo(repo *git.Repository, publicKeys *ssh.PublicKeys) (leftCommit, rightCommit *object.Commit, err error) {
+	// 获取拉取前 HEAD 指向的 Commit
+	leftRef, err := repo.Head()
+	if err != nil {
+		return nil, nil, err
+	}
+	leftCommit, err = repo.CommitObject(leftRef.Hash())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 拉取远端存储库的所有引用
+	err = repo.Fetch(&git.FetchOptions{
+		Auth:       publicKeys,
+		RemoteName: remoteName,
+		RefSpecs:   []config.RefSpec{"+refs/*:refs/*"},
+		Prune:      true,
+		Force:      true,
+	})
+	if err != nil {
+		return leftCommit, nil, err
+	}
+
+	// 获取拉取后 HEAD 指向的 Commit
+	rightRef, err := repo.Head()
+	if err != nil {
+		return leftCommit, nil, err
+	}
+	rightCommit, err = repo.CommitObject(rightRef.Hash())
+	if err != nil {
+		return leftCommit, nil, err
+	}
+
+	return leftCommit, rightCommit, nil
+}
+
+// GetRepoBranchNames 通过引用获取本地存储库的本地分支名，适用于没有工作树的裸存储库
+//
+// 参数：
+//   - repo: 本地存储库对象
+//
+// 返回：
+//   - 本地分支名
+//   - 错误信息
+func GetRepoBranchNames(repo *git.Repository) ([]string, error) {
+	branches, err := repo.Branches()
+	if err != nil {
+		return nil, err
+	}
+	defer branches.Close()
+
+	var branchNames []string
+	err = branches.ForEach(func(reference *plumbing.Reference) error {
+		branchNames = append(branchNames, reference.Name().Short())
+		return nil
+	})
+
+	return branchNames, err
+}
+
 // PullRepo 拉取远端存储库的更改到本地
 //
 // 参数：
@@ -102,6 +202,35 @@ func PullRepo(repo *git.Repository, publicKeys *ssh.PublicKeys) (worktree *git.W
 	return worktree, leftCommit, rightCommit, nil
 }
 
+// CommitsBetween 获取 rightCommit 可达而 leftCommit 不可达的提交，即从 leftCommit 更新到 rightCommit 时新增的提交
+//
+// 参数：
+//   - leftCommit: 更新前的提交
+//   - rightCommit: 更新后的提交
+//
+// 返回：
+//   - 新增的提交，按从新到旧的顺序排列
+//   - 错误信息
+func CommitsBetween(leftCommit, rightCommit *object.Commit) ([]*object.Commit, error) {
+	// leftCommit 的所有祖先
+	seen := make(map[plumbing.Hash]bool)
+	if err := object.NewCommitPreorderIter(leftCommit, nil, nil).ForEach(func(commit *object.Commit) error {
+		seen[commit.Hash] = true
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	var commits []*object.Commit
+	if err := object.NewCommitPreorderIter(rightCommit, seen, nil).ForEach(func(commit *object.Commit) error {
+		commits = append(commits, commit)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
 // IsLocalRepo 检测是不是本地存储库，是的话返回本地存储库对象及其 HEAD 指向的引用
 //
 // 参数：
@@ -124,6 +253,42 @@ func IsLocalRepo(path string) (bool, *git.Repository, *plumbing.Reference) {
 	return true, repo, headRef
 }
 
+// FindLocalRepos 在目录中递归查找本地存储库，找到存储库后不再深入其内部
+//
+// 参数：
+//   - root: 查找的根目录（不包括根目录本身）
+//   - skipPaths: 跳过的路径
+//
+// 返回：
+//   - 本地存储库路径
+//   - 错误信息
+func FindLocalRepos(root string, skipPaths []string) ([]string, error) {
+	var repoPaths []string
+
+	if !FileExist(root) {
+		return repoPaths, nil
+	}
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() || path == root {
+			return nil
+		}
+		if entry.Name() == ".git" || slices.Contains(skipPaths, path) {
+			return filepath.SkipDir
+		}
+		if isRepo, _, _ := IsLocalRepo(path); isRepo {
+			repoPaths = append(repoPaths, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	return repoPaths, err
+}
+
 // GetRepoHeadRef 获取本地存储库对象 HEAD 指向的引用
 //
 // 参数：
@@ -358,3 +523,159 @@ func CheckoutBranch(worktree *git.Worktree, branchName string) error {
 	})
 	return err
 }
+
+// IsPinnedRevision 检测 revision 是否固定到某个提交，即不是分支而是标签、Commit Hash 或其他引用
+//
+//   - 'refs/heads/<分支名>' 和本地或 origin 存在同名分支的 revision 视为分支
+//
+// 参数：
+//   - repo: 本地存储库对象
+//   - revision: 存储库配置中的 revision
+//
+// 返回：
+//   - 是否固定到某个提交
+func IsPinnedRevision(repo *git.Repository, revision string) bool {
+	if revision == "" || strings.HasPrefix(revision, "refs/heads/") {
+		return false
+	}
+	if _, err := repo.Reference(plumbing.NewBranchReferenceName(revision), false); err == nil {
+		return false
+	}
+	if _, err := repo.Reference(plumbing.NewRemoteReferenceName(remoteName, revision), false); err == nil {
+		return false
+	}
+	return true
+}
+
+// ResolveRevision 将 revision 解析为 Commit 的 Hash 值，本地无法解析时先从 origin 拉取所有标签和分支
+//
+//   - 支持分支名、标签名、'refs/...' 形式的引用和（缩写的）Commit Hash
+//
+// 参数：
+//   - repo: 本地存储库对象
+//   - revision: 存储库配置中的 revision
+//   - publicKeys: ssh 公钥
+//
+// 返回：
+//   - Commit 的 Hash 值
+//   - 错误信息
+func ResolveRevision(repo *git.Repository, revision string, publicKeys *ssh.PublicKeys) (plumbing.Hash, error) {
+	if hash, err := repo.ResolveRevision(plumbing.Revision(revision)); err == nil {
+		return *hash, nil
+	}
+	err := repo.Fetch(&git.FetchOptions{
+		Auth:       publicKeys,
+		RemoteName: remoteName,
+		Tags:       git.AllTags,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return plumbing.ZeroHash, err
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("Revision %s: %s", revision, err)
+	}
+	return *hash, nil
+}
+
+// CheckoutRevision 将工作树检出到 revision
+//
+//   - revision 是分支时切换到该分支，否则检出到其指向的提交，HEAD 处于分离状态
+//
+// 参数：
+//   - repo: 本地存储库对象
+//   - revision: 存储库配置中的 revision
+//   - publicKeys: ssh 公钥
+//
+// 返回：
+//   - 错误信息
+func CheckoutRevision(repo *git.Repository, revision string, publicKeys *ssh.PublicKeys) error {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	if !IsPinnedRevision(repo, revision) {
+		branchName := strings.TrimPrefix(revision, "refs/heads/")
+		// 本地分支不存在时根据 origin 的同名分支创建
+		if _, err := repo.Reference(plumbing.NewBranchReferenceName(branchName), false); err != nil {
+			remoteReference, err := repo.Reference(plumbing.NewRemoteReferenceName(remoteName, branchName), true)
+			if err != nil {
+				return fmt.Errorf("Branch %s: %s", branchName, err)
+			}
+			repo.CreateBranch(&config.Branch{
+				Name:   branchName,
+				Remote: remoteName,
+				Merge:  plumbing.NewBranchReferenceName(branchName),
+			})
+			if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(branchName), remoteReference.Hash())); err != nil {
+				return err
+			}
+		}
+		return CheckoutBranch(worktree, branchName)
+	}
+	hash, err := ResolveRevision(repo, revision, publicKeys)
+	if err != nil {
+		return err
+	}
+	return CheckoutCommit(repo, hash.String(), publicKeys)
+}
+
+// PullRepoToRevision 拉取远端存储库的更改到本地，revision 固定到某个提交时检出到该提交
+//
+//   - revision 为空或是分支时与 PullRepo 相同
+//   - revision 固定到某个提交时拉取后检出到 revision 指向的提交，已处于该提交时返回 git.NoErrAlreadyUpToDate
+//
+// 参数：
+//   - repo: 本地存储库对象
+//   - revision: 存储库配置中的 revision
+//   - publicKeys: ssh 公钥
+//
+// 返回：
+//   - 存储库的 git 工作树对象
+//   - 拉取前本地最新 Commit 的 Hash 值
+//   - 拉取后本地最新 Commit 的 Hash 值
+//   - 错误信息
+func PullRepoToRevision(repo *git.Repository, revision string, publicKeys *ssh.PublicKeys) (worktree *git.Worktree, leftCommit, rightCommit *object.Commit, err error) {
+	if !IsPinnedRevision(repo, revision) {
+		return PullRepo(repo, publicKeys)
+	}
+
+	worktree, err = repo.Worktree()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	leftRef, err := repo.Head()
+	if err != nil {
+		return worktree, nil, nil, err
+	}
+	leftCommit, err = repo.CommitObject(leftRef.Hash())
+	if err != nil {
+		return worktree, nil, nil, err
+	}
+
+	// 拉取远端存储库的更改，revision 可能是新的标签
+	err = repo.Fetch(&git.FetchOptions{
+		Auth:       publicKeys,
+		RemoteName: remoteName,
+		Tags:       git.AllTags,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return worktree, nil, nil, err
+	}
+	hash, err := ResolveRevision(repo, revision, publicKeys)
+	if err != nil {
+		return worktree, nil, nil, err
+	}
+	if hash == leftCommit.Hash {
+		return worktree, nil, nil, git.NoErrAlreadyUpToDate
+	}
+	if err := CheckoutCommit(repo, hash.String(), publicKeys); err != nil {
+		return worktree, nil, nil, err
+	}
+	rightCommit, err = repo.CommitObject(hash)
+	if err != nil {
+		return worktree, nil, nil, err
+	}
+
+	return worktree, leftCommit, rightCommit, nil
+}
@@ -0,0 +1,189 @@
+/*
+File: define_history.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 00:21:48
+
+Description: 拉取记录和提交变更
+
+- 每次成功拉取后在拉取记录文件中追加一行 JSON，供 'changes' 子命令查看一段时间内拉取的变更
+*/
+
+package general
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// PullRecord 拉取记录
+type PullRecord struct {
+	Time  time.Time `json:"time"`  // 拉取时间
+	Repo  string    `json:"repo"`  // 存储库名
+	Path  string    `json:"path"`  // 本地存储库路径
+	Left  string    `json:"left"`  // 拉取前的提交
+	Right string    `json:"right"` // 拉取后的提交
+}
+
+var sinceRegexp = regexp.MustCompile(`^(\d+)(w|d)$`) // 以天或周为单位的时间段
+
+// AppendPullRecord 追加拉取记录
+//
+// 参数：
+//   - filePath: 拉取记录文件路径
+//   - record: 拉取记录
+//
+// 返回：
+//   - 错误信息
+func AppendPullRecord(filePath string, record PullRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := CreateFile(filePath); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// ReadPullRecords 读取指定时间之后的拉取记录
+//
+// 参数：
+//   - filePath: 拉取记录文件路径
+//   - since: 起始时间
+//
+// 返回：
+//   - 按时间顺序排列的拉取记录，文件不存在时为空
+//   - 错误信息
+func ReadPullRecords(filePath string, since time.Time) ([]PullRecord, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []PullRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record PullRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue // 跳过损坏的行
+		}
+		if !record.Time.Before(since) {
+			records = append(records, record)
+		}
+	}
+	return records, scanner.Err()
+}
+
+// ParseSince 解析起始时间
+//
+//   - 支持 Go 的时间段（例如 '36h'）、以天或周为单位的时间段（例如 '7d'、'2w'）、'today'、'yesterday' 和 'YYYY-MM-DD' 格式的日期
+//
+// 参数：
+//   - value: 待解析的字符串
+//
+// 返回：
+//   - 起始时间
+//   - 错误信息
+func ParseSince(value string) (time.Time, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch value {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+	if matches := sinceRegexp.FindStringSubmatch(value); matches != nil {
+		number, _ := strconv.Atoi(matches[1])
+		if matches[2] == "w" {
+			number *= 7
+		}
+		return now.AddDate(0, 0, -number), nil
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	if date, err := time.ParseInLocation(time.DateOnly, value, now.Location()); err == nil {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("Invalid time '%s', use a duration (e.g. '24h', '7d'), 'today', 'yesterday' or a date (YYYY-MM-DD)", value)
+}
+
+// RelativeTime 将时间转换为相对当前时间的描述，例如 '3 hours ago'
+//
+// 参数：
+//   - t: 时间
+//
+// 返回：
+//   - 相对时间描述
+func RelativeTime(t time.Time) string {
+	elapsed := time.Since(t)
+	units := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"week", 7 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, unit := range units {
+		if count := int(elapsed / unit.duration); count > 0 {
+			if count == 1 {
+				return fmt.Sprintf("1 %s ago", unit.name)
+			}
+			return fmt.Sprintf("%d %ss ago", count, unit.name)
+		}
+	}
+	return "just now"
+}
+
+// CommitSubject 获取提交信息的第一行
+//
+// 参数：
+//   - commit: 提交
+//
+// 返回：
+//   - 提交信息的第一行
+func CommitSubject(commit *object.Commit) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+	return subject
+}
+
+// DiffStat 获取两个提交之间的文件变更统计
+//
+// 参数：
+//   - leftCommit: 旧提交
+//   - rightCommit: 新提交
+//
+// 返回：
+//   - 每个文件的增删行数
+//   - 错误信息
+func DiffStat(leftCommit, rightCommit *object.Commit) (object.FileStats, error) {
+	patch, err := leftCommit.Patch(rightCommit)
+	if err != nil {
+		return nil, err
+	}
+	return patch.Stats(), nil
+}
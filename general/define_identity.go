@@ -0,0 +1,244 @@
+/*
+File: define_identity.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 21:47:05
+
+Description: SSH 私钥管理
+
+- 支持 ed25519、ecdsa 和 rsa 私钥，以及与私钥同名的 OpenSSH 证书（<私钥>-cert.pub）
+- 私钥按需加载：服务器接受公钥后才读取私钥并询问密码，每个私钥最多询问一次
+*/
+
+package general
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	cssh "golang.org/x/crypto/ssh"
+)
+
+var defaultIdentityNames = []string{"id_ed25519", "id_ecdsa", "id_rsa"} // 按优先级排列的默认私钥文件名
+
+var (
+	identityMutex   sync.Mutex                     // 保护私钥缓存
+	identitySigners = make(map[string]cssh.Signer) // 私钥文件到签名器的缓存，保证每个私钥只询问一次密码
+	identityErrors  = make(map[string]error)       // 私钥文件到加载错误的缓存
+)
+
+var (
+	authMutex      sync.Mutex                       // 保护已注册的私钥
+	defaultSigners []cssh.Signer                    // 所有存储库源共用的私钥
+	sourceSigners  = make(map[string][]cssh.Signer) // 存储库源地址到其专用私钥的映射
+)
+
+// DetectIdentityFiles 检测当前用户已有的默认私钥文件
+//
+// 返回：
+//   - 按 ed25519、ecdsa、rsa 顺序排列的私钥文件
+func DetectIdentityFiles() []string {
+	var identityFiles []string
+	for _, identityName := range defaultIdentityNames {
+		identityFile := filepath.Join(UserInfo.HomeDir, ".ssh", identityName)
+		if FileExist(identityFile) {
+			identityFiles = append(identityFiles, identityFile)
+		}
+	}
+	return identityFiles
+}
+
+// GetIdentityFiles 获取配置的私钥文件
+//
+//   - ssh.identity_files 在前，旧配置项 ssh.rsa_file 在后
+//
+// 参数：
+//   - config: 配置项
+//
+// 返回：
+//   - 按尝试顺序排列的私钥文件
+func GetIdentityFiles(config *Config) []string {
+	identityFiles := slices.Clone(config.SSH.IdentityFiles)
+	if config.SSH.RsaFile != "" && !slices.Contains(identityFiles, config.SSH.RsaFile) {
+		identityFiles = append(identityFiles, config.SSH.RsaFile)
+	}
+	return identityFiles
+}
+
+// GetSSHAuth 根据配置项创建 SSH 认证方式
+//
+//   - 存储库源的 identity_files 先于 ssh.identity_files 尝试
+//   - 同时设置主机公钥校验方法（参见 SetHostKeyCallback）、各存储库源的代理（参见 SetProxies）和 HTTPS 访问令牌（参见 SetCredentialSources）
+//
+// 参数：
+//   - config: 配置项
+//
+// 返回：
+//   - ssh 公钥
+//   - 错误信息，没有可用的私钥时返回
+func GetSSHAuth(config *Config) (*ssh.PublicKeys, error) {
+	signers, err := newIdentitySigners(GetIdentityFiles(config))
+	if err != nil {
+		return nil, err
+	}
+	sources := GetSources(config)
+	hostSigners := make(map[string][]cssh.Signer)
+	for _, sourceName := range sortedKeys(sources) {
+		source := sources[sourceName]
+		if len(source.IdentityFiles) == 0 {
+			continue
+		}
+		signers, err := newIdentitySigners(source.IdentityFiles)
+		if err != nil {
+			return nil, fmt.Errorf("Source %s: %s", sourceName, err)
+		}
+		hostSigners[source.Url] = append(hostSigners[source.Url], signers...)
+	}
+	if len(signers) == 0 && len(hostSigners) == 0 {
+		return nil, fmt.Errorf("No SSH key found, set ssh.identity_files")
+	}
+
+	authMutex.Lock()
+	defaultSigners, sourceSigners = signers, hostSigners
+	authMutex.Unlock()
+
+	publicKeys := &ssh.PublicKeys{User: "git"}
+	if len(signers) > 0 {
+		publicKeys.Signer = signers[0]
+	}
+	if err := SetHostKeyCallback(publicKeys, config); err != nil {
+		return nil, err
+	}
+	SetProxies(config)
+	SetCredentialSources(config)
+	return publicKeys, nil
+}
+
+// newIdentitySigners 为私钥文件创建按需加载的签名器
+//
+//   - 不存在的私钥文件会被跳过，私钥有同名证书时证书在前
+//
+// 参数：
+//   - identityFiles: 私钥文件
+//
+// 返回：
+//   - 签名器
+//   - 错误信息
+func newIdentitySigners(identityFiles []string) ([]cssh.Signer, error) {
+	var signers []cssh.Signer
+	for _, identityFile := range identityFiles {
+		if !FileExist(identityFile) {
+			continue
+		}
+		publicKey, err := readIdentityPublicKey(identityFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", identityFile, err)
+		}
+		if certData, err := os.ReadFile(identityFile + "-cert.pub"); err == nil {
+			if certKey, _, _, _, err := cssh.ParseAuthorizedKey(certData); err == nil {
+				if cert, ok := certKey.(*cssh.Certificate); ok {
+					signers = append(signers, &lazySigner{identityFile: identityFile, publicKey: cert})
+				}
+			}
+		}
+		signers = append(signers, &lazySigner{identityFile: identityFile, publicKey: publicKey})
+	}
+	return signers, nil
+}
+
+// readIdentityPublicKey 不询问密码读取私钥对应的公钥
+//
+//   - 依次尝试未加密的私钥、OpenSSH 格式加密私钥中的公钥和同名的 .pub 文件，都不可用时才加载私钥
+//
+// 参数：
+//   - identityFile: 私钥文件路径
+//
+// 返回：
+//   - 公钥
+//   - 错误信息
+func readIdentityPublicKey(identityFile string) (cssh.PublicKey, error) {
+	data, err := os.ReadFile(identityFile)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := cssh.ParsePrivateKey(data)
+	if err == nil {
+		return signer.PublicKey(), nil
+	}
+	if passphraseErr, ok := err.(*cssh.PassphraseMissingError); ok && passphraseErr.PublicKey != nil {
+		return passphraseErr.PublicKey, nil
+	}
+	if publicData, err := os.ReadFile(identityFile + ".pub"); err == nil {
+		if publicKey, _, _, _, err := cssh.ParseAuthorizedKey(publicData); err == nil {
+			return publicKey, nil
+		}
+	}
+	signer, err = LoadIdentity(identityFile)
+	if err != nil {
+		return nil, err
+	}
+	return signer.PublicKey(), nil
+}
+
+// lazySigner 服务器接受公钥后才加载私钥的签名器
+type lazySigner struct {
+	identityFile string         // 私钥文件路径
+	publicKey    cssh.PublicKey // 公钥或证书
+}
+
+func (s *lazySigner) PublicKey() cssh.PublicKey {
+	return s.publicKey
+}
+
+func (s *lazySigner) Sign(rand io.Reader, data []byte) (*cssh.Signature, error) {
+	return s.SignWithAlgorithm(rand, data, "")
+}
+
+func (s *lazySigner) SignWithAlgorithm(rand io.Reader, data []byte, algorithm string) (*cssh.Signature, error) {
+	signer, err := LoadIdentity(s.identityFile)
+	if err != nil {
+		return nil, err
+	}
+	if algorithmSigner, ok := signer.(cssh.AlgorithmSigner); ok {
+		return algorithmSigner.SignWithAlgorithm(rand, data, algorithm)
+	}
+	return signer.Sign(rand, data)
+}
+
+// LoadIdentity 加载私钥文件，需要密码时询问用户，结果会被缓存，因此每个私钥最多询问一次密码
+//
+// 参数：
+//   - identityFile: 私钥文件路径
+//
+// 返回：
+//   - 签名器
+//   - 错误信息
+func LoadIdentity(identityFile string) (cssh.Signer, error) {
+	identityMutex.Lock()
+	defer identityMutex.Unlock()
+
+	if signer, ok := identitySigners[identityFile]; ok {
+		return signer, nil
+	}
+	if err, ok := identityErrors[identityFile]; ok {
+		return nil, err
+	}
+
+	// 询问密码期间暂停等待动画
+	if WaitSpinner.Active() {
+		WaitSpinner.Stop()
+		defer WaitSpinner.Start()
+	}
+	publicKeys, err := GetPublicKeysByGit(identityFile)
+	if err != nil {
+		identityErrors[identityFile] = err
+		return nil, err
+	}
+	identitySigners[identityFile] = publicKeys.Signer
+	return publicKeys.Signer, nil
+}
@@ -0,0 +1,210 @@
+/*
+File: define_lock.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 10:20:41
+
+Description: 操作工作区锁文件
+*/
+
+package general
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/pelletier/go-toml"
+)
+
+var commitHashRegex = regexp.MustCompile(`^[0-9a-f]{40}$`) // 完整的 Commit Hash 值
+
+// 用于转换锁文件的结构体
+type Lock struct {
+	Repos []LockedRepo `toml:"repo"`
+}
+type LockedRepo struct {
+	Name       string            `toml:"name"`
+	Source     string            `toml:"source"`
+	Url        string            `toml:"url"`
+	Branch     string            `toml:"branch"`
+	Hash       string            `toml:"hash"`
+	Submodules []LockedSubmodule `toml:"submodule"`
+}
+type LockedSubmodule struct {
+	Name string `toml:"name"`
+	Path string `toml:"path"`
+	Hash string `toml:"hash"`
+}
+
+// ReadLockFile 读取锁文件
+//
+// 参数：
+//   - filePath: 锁文件路径
+//
+// 返回：
+//   - 锁文件内容
+//   - 错误信息
+func ReadLockFile(filePath string) (*Lock, error) {
+	if !FileExist(filePath) {
+		return nil, fmt.Errorf("Open %s: no such file or directory", filePath)
+	}
+	tree, err := toml.LoadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var lock Lock
+	if err := tree.Unmarshal(&lock); err != nil {
+		return nil, err
+	}
+
+	// 锁文件可能被手动修改，检测 Hash 值是否完整
+	for _, lockedRepo := range lock.Repos {
+		if !commitHashRegex.MatchString(lockedRepo.Hash) {
+			return nil, fmt.Errorf("Invalid hash '%s' of repository %s in %s: must be 40 hexadecimal characters", lockedRepo.Hash, lockedRepo.Name, filePath)
+		}
+		for _, lockedSubmodule := range lockedRepo.Submodules {
+			if !commitHashRegex.MatchString(lockedSubmodule.Hash) {
+				return nil, fmt.Errorf("Invalid hash '%s' of submodule %s/%s in %s: must be 40 hexadecimal characters", lockedSubmodule.Hash, lockedRepo.Name, lockedSubmodule.Name, filePath)
+			}
+		}
+	}
+	return &lock, nil
+}
+
+// WriteLockFile 写入锁文件，已存在则覆盖
+//
+// 参数：
+//   - filePath: 锁文件路径
+//   - lock: 锁文件内容
+//
+// 返回：
+//   - 错误信息
+func WriteLockFile(filePath string, lock *Lock) error {
+	data, err := toml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	if err := CreateFile(filePath); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+// GetRepoOriginUrl 获取本地存储库 origin 的地址
+//
+// 参数：
+//   - repo: 本地存储库对象
+//
+// 返回：
+//   - origin 地址，不存在时为空字符串
+func GetRepoOriginUrl(repo *git.Repository) string {
+	remote, err := repo.Remote(remoteName)
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+	return remote.Config().URLs[0]
+}
+
+// GetRepoPushUrls 获取本地存储库 origin 的 pushurl
+//
+// 参数：
+//   - repo: 本地存储库对象
+//
+// 返回：
+//   - pushurl，不存在时为空
+func GetRepoPushUrls(repo *git.Repository) []string {
+	repoConfig, err := repo.Config()
+	if err != nil {
+		return nil
+	}
+	return repoConfig.Raw.Section("remote").Subsection(remoteName).OptionAll("pushurl")
+}
+
+// SetRepoOriginUrl 将本地存储库 origin 的地址替换为新地址
+//
+//   - pushurl 中已有新地址（新地址原本是镜像）时将其移到首位，原地址保留为镜像，否则将原地址替换为新地址
+//
+// 参数：
+//   - repo: 本地存储库对象
+//   - newUrl: 新地址
+//
+// 返回：
+//   - 错误信息
+func SetRepoOriginUrl(repo *git.Repository, newUrl string) error {
+	repoConfig, err := repo.Config()
+	if err != nil {
+		return err
+	}
+	remote, ok := repoConfig.Remotes[remoteName]
+	if !ok || len(remote.URLs) == 0 {
+		return fmt.Errorf("Remote %s not found", remoteName)
+	}
+	oldUrl := remote.URLs[0]
+	remote.URLs[0] = newUrl
+
+	// go-git 不解析 pushurl，直接修改原始配置
+	subsection := repoConfig.Raw.Section("remote").Subsection(remoteName)
+	if pushUrls := subsection.OptionAll("pushurl"); len(pushUrls) > 0 {
+		newPushUrls := []string{newUrl}
+		mirrored := slices.Contains(pushUrls, newUrl)
+		for _, pushUrl := range pushUrls {
+			if pushUrl == newUrl || (pushUrl == oldUrl && !mirrored) {
+				continue
+			}
+			newPushUrls = append(newPushUrls, pushUrl)
+		}
+		subsection.RemoveOption("pushurl")
+		for _, pushUrl := range newPushUrls {
+			subsection.AddOption("pushurl", pushUrl)
+		}
+	}
+
+	return repo.SetConfig(repoConfig)
+}
+
+// CheckoutCommit 将工作树检出到指定 Commit，本地不存在该 Commit 时先从 origin 拉取
+//
+//   - 检出后 HEAD 处于分离状态
+//   - 裸镜像存储库没有工作树，无法检出
+//
+// 参数：
+//   - repo: 本地存储库对象
+//   - hash: Commit 的 Hash 值
+//   - publicKeys: ssh 公钥
+//
+// 返回：
+//   - 错误信息
+func CheckoutCommit(repo *git.Repository, hash string, publicKeys *ssh.PublicKeys) error {
+	worktree, err := repo.Worktree()
+	if err == git.ErrIsBareRepository {
+		return fmt.Errorf("Cannot check out commit %s in a bare mirror repository", hash)
+	}
+	if err != nil {
+		return err
+	}
+	commitHash := plumbing.NewHash(hash)
+
+	// 本地不存在指定 Commit 时先拉取
+	if _, err := repo.CommitObject(commitHash); err != nil {
+		err = repo.Fetch(&git.FetchOptions{
+			Auth:       publicKeys,
+			RemoteName: remoteName,
+		})
+		if err != nil && err != git.NoErrAlreadyUpToDate {
+			return err
+		}
+		if _, err := repo.CommitObject(commitHash); err != nil {
+			return fmt.Errorf("Commit %s: %s", hash, err)
+		}
+	}
+
+	return worktree.Checkout(&git.CheckoutOptions{
+		Hash:  commitHash,
+		Force: false, // 如果有未提交的更改，不强制切换（否则会丢弃本地更改）
+	})
+}
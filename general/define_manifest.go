@@ -0,0 +1,245 @@
+/*
+File: define_manifest.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 11:15:20
+
+Description: 操作 repo 工具的 XML 清单文件
+
+- 清单中的 <remote> 对应存储库源，<project> 对应存储库
+*/
+
+package general
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// 用于转换 XML 清单的结构体
+type Manifest struct {
+	XMLName  xml.Name          `xml:"manifest"`
+	Remotes  []ManifestRemote  `xml:"remote"`
+	Default  *ManifestDefault  `xml:"default,omitempty"`
+	Projects []ManifestProject `xml:"project"`
+}
+type ManifestRemote struct {
+	Name     string `xml:"name,attr"`
+	Fetch    string `xml:"fetch,attr"`
+	Revision string `xml:"revision,attr,omitempty"`
+}
+type ManifestDefault struct {
+	Remote   string `xml:"remote,attr,omitempty"`
+	Revision string `xml:"revision,attr,omitempty"`
+}
+type ManifestProject struct {
+	Name     string `xml:"name,attr"`
+	Path     string `xml:"path,attr,omitempty"`
+	Remote   string `xml:"remote,attr,omitempty"`
+	Revision string `xml:"revision,attr,omitempty"`
+}
+
+// ReadManifest 读取 XML 清单文件
+//
+// 参数：
+//   - filePath: 清单文件路径
+//
+// 返回：
+//   - 清单
+//   - 错误信息
+func ReadManifest(filePath string) (*Manifest, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := xml.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// WriteManifest 将清单以 XML 格式写入 writer
+//
+// 参数：
+//   - writer: 写入目标
+//   - manifest: 清单
+//
+// 返回：
+//   - 错误信息
+func WriteManifest(writer io.Writer, manifest *Manifest) error {
+	data, err := xml.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	_, err = writer.Write(append(data, '\n'))
+	return err
+}
+
+// ImportManifest 将清单合并到配置项中
+//
+//   - 与已有存储库源地址和用户名都相同的 <remote> 直接使用已有存储库源，否则添加到 [source]
+//   - 清单中的 path 和 revision 写入 [repo.<存储库名>]
+//   - 相对的 fetch 地址（例如 '..'）与 repo 工具一样相对于清单存储库的地址解析
+//
+// 参数：
+//   - config: 配置项
+//   - manifest: 清单
+//   - manifestUrl: 清单存储库的地址，清单中没有相对的 fetch 地址时可以为空
+//
+// 返回：
+//   - 新增的存储库键
+//   - 错误信息
+func ImportManifest(config *Config, manifest *Manifest, manifestUrl string) ([]string, error) {
+	// 解析所有 <remote>
+	remotes := make(map[string]ManifestRemote)
+	remoteHosts := make(map[string]string)
+	remotePaths := make(map[string]string)
+	remoteSchemes := make(map[string]string)
+	for _, remote := range manifest.Remotes {
+		fetchUrl := remote.Fetch
+		if strings.HasPrefix(fetchUrl, ".") {
+			resolvedUrl, err := resolveFetchUrl(manifestUrl, fetchUrl)
+			if err != nil {
+				return nil, fmt.Errorf("Remote %s: %s", remote.Name, err)
+			}
+			fetchUrl = resolvedUrl
+		}
+		host, path, err := ParseRepoUrl(fetchUrl)
+		if err != nil {
+			return nil, fmt.Errorf("Remote %s: %s", remote.Name, err)
+		}
+		remotes[remote.Name] = remote
+		remoteHosts[remote.Name] = host
+		remotePaths[remote.Name] = path
+		remoteSchemes[remote.Name] = RepoUrlScheme(fetchUrl)
+	}
+	defaultRemote, defaultRevision := "", ""
+	if manifest.Default != nil {
+		defaultRemote, defaultRevision = manifest.Default.Remote, manifest.Default.Revision
+	}
+
+	var addedRepos []string
+	for _, project := range manifest.Projects {
+		projectRemote := project.Remote
+		if projectRemote == "" {
+			projectRemote = defaultRemote
+		}
+		if _, ok := remotes[projectRemote]; !ok {
+			return addedRepos, fmt.Errorf("Project %s: remote '%s' is not defined", project.Name, projectRemote)
+		}
+
+		// <remote> 的 fetch 路径和 <project> 的 name 共同组成 '<用户名>/<存储库名>'
+		fullName := strings.Trim(strings.Join([]string{remotePaths[projectRemote], project.Name}, "/"), "/")
+		slashIndex := strings.LastIndex(fullName, "/")
+		if slashIndex <= 0 {
+			return addedRepos, fmt.Errorf("Project %s: unable to determine the owner", project.Name)
+		}
+		username, repoName := fullName[:slashIndex], fullName[slashIndex+1:]
+
+		// <remote> 的 fetch 路径完整时以 remote 名作为新存储库源的名称
+		sourceName := projectRemote
+		if username != remotePaths[projectRemote] {
+			sourceName = projectRemote + "-" + strings.ReplaceAll(username, "/", "-")
+		}
+
+		// 确定 revision
+		revision := defaultRevision
+		if project.Revision != "" {
+			revision = project.Revision
+		} else if remotes[projectRemote].Revision != "" {
+			revision = remotes[projectRemote].Revision
+		}
+
+		source := SourceConfig{Url: remoteHosts[projectRemote], Username: username, Scheme: remoteSchemes[projectRemote]}
+		if repoKey, added := MergeRepo(config, source, repoName, project.Path, revision, sourceName); added {
+			addedRepos = append(addedRepos, repoKey)
+		}
+	}
+
+	return addedRepos, nil
+}
+
+// resolveFetchUrl 将相对的 fetch 地址解析为绝对地址
+//
+//   - 与 repo 工具相同，以清单存储库的地址为基准解析，例如 'https://host/platform/manifest' 和 '..' 得到 'https://host/'
+//
+// 参数：
+//   - manifestUrl: 清单存储库的地址
+//   - fetchUrl: 相对的 fetch 地址
+//
+// 返回：
+//   - 绝对地址
+//   - 错误信息
+func resolveFetchUrl(manifestUrl, fetchUrl string) (string, error) {
+	if manifestUrl == "" {
+		return "", fmt.Errorf("Relative fetch url '%s' requires the url of the manifest repository (manifest import --manifest-url)", fetchUrl)
+	}
+	// 'git@host:path' 格式转换为 'ssh://git@host/path' 格式
+	if !strings.Contains(manifestUrl, "://") {
+		colonIndex := strings.Index(manifestUrl, ":")
+		if colonIndex <= 0 || strings.Contains(manifestUrl[:colonIndex], "/") {
+			return "", fmt.Errorf("Unsupported manifest url: %s", manifestUrl)
+		}
+		manifestUrl = "ssh://" + manifestUrl[:colonIndex] + "/" + strings.TrimPrefix(manifestUrl[colonIndex+1:], "/")
+	}
+	baseUrl, err := url.Parse(strings.TrimSuffix(manifestUrl, "/"))
+	if err != nil {
+		return "", err
+	}
+	relativeUrl, err := url.Parse(fetchUrl)
+	if err != nil {
+		return "", err
+	}
+	return baseUrl.ResolveReference(relativeUrl).String(), nil
+}
+
+// ExportManifest 将配置项转换为清单
+//
+// 参数：
+//   - config: 配置项
+//   - defaultSource: 作为 <default> 的存储库源
+//
+// 返回：
+//   - 清单
+func ExportManifest(config *Config, defaultSource string) *Manifest {
+	manifest := &Manifest{Default: &ManifestDefault{Remote: defaultSource}}
+
+	// 存储库源
+	sources := GetSources(config)
+	for _, name := range sortedKeys(sources) {
+		fetchUrl := "ssh://git@" + sources[name].Url + "/" + sources[name].Username
+		if scheme := sources[name].Scheme; scheme == "https" || scheme == "http" {
+			fetchUrl = scheme + "://" + sources[name].Url + "/" + sources[name].Username
+		}
+		manifest.Remotes = append(manifest.Remotes, ManifestRemote{
+			Name:  name,
+			Fetch: fetchUrl,
+		})
+	}
+
+	// 存储库
+	repoNames := slices.Clone(config.Git.Repos)
+	sort.Strings(repoNames)
+	for _, repoName := range repoNames {
+		project := ManifestProject{Name: RepoBaseName(repoName), Path: getRelativeRepoPath(config, repoName)}
+		if repoConfig, ok := config.Repo[repoName]; ok {
+			if repoConfig.Source != "" && repoConfig.Source != defaultSource {
+				project.Remote = repoConfig.Source
+			}
+			project.Revision = repoConfig.Revision
+		}
+		manifest.Projects = append(manifest.Projects, project)
+	}
+
+	return manifest
+}
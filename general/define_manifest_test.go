@@ -0,0 +1,48 @@
+/*
+File: define_manifest_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 11:38:26
+
+Description: 测试 repo 工具的 XML 清单的导入
+*/
+
+package general
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestImportManifestFetchPort fetch 地址带有非默认端口且 ssh_config 中没有该端口时拒绝导入，有时导入为该主机的存储库源
+func TestImportManifestFetchPort(t *testing.T) {
+	manifest, err := ReadManifest(filepath.Join("testdata", "manifest", "port.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	useTestSSHConfig(t, "")
+	config := &Config{Storage: StorageConfig{Path: t.TempDir()}}
+	if _, err := ImportManifest(config, manifest, ""); err == nil || !strings.Contains(err.Error(), "Port 29418") {
+		t.Fatalf("err = %v, want a hint to set Port 29418 in ssh_config", err)
+	}
+	if len(config.Git.Repos) != 0 || len(config.Source) != 0 {
+		t.Errorf("config = %+v, want nothing imported", config)
+	}
+
+	useTestSSHConfig(t, "Host review.example.com\n  Port 29418\n")
+	addedRepos, err := ImportManifest(config, manifest, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addedRepos) != 1 || addedRepos[0] != "build" {
+		t.Fatalf("added repos = %v, want [build]", addedRepos)
+	}
+	if source := config.Source["review"]; source.Url != "review.example.com" || source.Username != "platform" {
+		t.Errorf("source = %+v, want review.example.com/platform", source)
+	}
+	if got := GetRepoPath(config, "build"); got != filepath.Join(config.Storage.Path, "tools", "build") {
+		t.Errorf("path = %s, want tools/build", got)
+	}
+}
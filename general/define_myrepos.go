@@ -0,0 +1,278 @@
+/*
+File: define_myrepos.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 13:32:07
+
+Description: 操作 myrepos 的 .mrconfig 文件
+
+- .mrconfig 中每个节的名称是存储库的本地路径，存储库地址和分支从该节的 checkout 命令（git clone）中解析
+*/
+
+package general
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// 用于转换 .mrconfig 文件的结构体
+type MrConfig struct {
+	Repos []MrRepo
+}
+type MrRepo struct {
+	Path   string // 存储库本地路径
+	Url    string // 存储库地址
+	Branch string // 存储库分支
+}
+
+// ReadMrConfig 读取 .mrconfig 文件
+//
+//   - 没有 checkout 命令或 checkout 命令不是 'git clone' 的节会被忽略
+//
+// 参数：
+//   - filePath: .mrconfig 文件路径
+//
+// 返回：
+//   - .mrconfig 文件内容
+//   - 错误信息
+func ReadMrConfig(filePath string) (*MrConfig, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	mrConfig := &MrConfig{}
+	section := ""                    // 当前节名称
+	checkouts := map[string]string{} // 节名称到 checkout 命令的映射
+	var sections []string            // 保持节的原始顺序
+	lastKey := ""                    // 上一个键，用于处理续行
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmedLine := strings.TrimSpace(line)
+
+		switch {
+		case trimmedLine == "" || strings.HasPrefix(trimmedLine, "#") || strings.HasPrefix(trimmedLine, ";"):
+			continue
+		case strings.HasPrefix(trimmedLine, "[") && strings.HasSuffix(trimmedLine, "]"): // 节
+			section = strings.TrimSpace(trimmedLine[1 : len(trimmedLine)-1])
+			sections = append(sections, section)
+			lastKey = ""
+		case line[0] == ' ' || line[0] == '\t': // 续行
+			if lastKey == "checkout" {
+				checkouts[section] += " " + strings.TrimSuffix(trimmedLine, "\\")
+			}
+		default: // 键值对
+			key, value, found := strings.Cut(trimmedLine, "=")
+			if !found {
+				continue
+			}
+			lastKey = strings.TrimSpace(key)
+			if lastKey == "checkout" {
+				checkouts[section] = strings.TrimSuffix(strings.TrimSpace(value), "\\")
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, section := range sections {
+		checkout, ok := checkouts[section]
+		if !ok || strings.EqualFold(section, "DEFAULT") {
+			continue
+		}
+		url, branch, ok := parseGitClone(checkout)
+		if !ok {
+			continue
+		}
+		mrConfig.Repos = append(mrConfig.Repos, MrRepo{Path: section, Url: url, Branch: branch})
+	}
+
+	return mrConfig, nil
+}
+
+// WriteMrConfig 将 .mrconfig 文件内容写入 writer
+//
+// 参数：
+//   - writer: 写入目标
+//   - mrConfig: .mrconfig 文件内容
+//
+// 返回：
+//   - 错误信息
+func WriteMrConfig(writer io.Writer, mrConfig *MrConfig) error {
+	for index, mrRepo := range mrConfig.Repos {
+		if index > 0 {
+			if _, err := io.WriteString(writer, "\n"); err != nil {
+				return err
+			}
+		}
+		branchOption := ""
+		if mrRepo.Branch != "" {
+			branchOption = fmt.Sprintf("-b '%s' ", mrRepo.Branch)
+		}
+		_, err := fmt.Fprintf(writer, "[%s]\ncheckout = git clone %s'%s' '%s'\n", mrRepo.Path, branchOption, mrRepo.Url, filepath.Base(mrRepo.Path))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportMrConfig 将 .mrconfig 文件内容合并到配置项中
+//
+// 参数：
+//   - config: 配置项
+//   - mrConfig: .mrconfig 文件内容
+//
+// 返回：
+//   - 新增的存储库键
+//   - 错误信息
+func ImportMrConfig(config *Config, mrConfig *MrConfig) ([]string, error) {
+	var addedRepos []string
+	for _, mrRepo := range mrConfig.Repos {
+		url, username, repoName, err := SplitRepoUrl(mrRepo.Url)
+		if err != nil {
+			return addedRepos, fmt.Errorf("Repository %s: %s", mrRepo.Path, err)
+		}
+		source := SourceConfig{Url: url, Username: username, Scheme: RepoUrlScheme(mrRepo.Url)}
+		if repoKey, added := MergeRepo(config, source, repoName, filepath.ToSlash(filepath.Clean(mrRepo.Path)), mrRepo.Branch, NewSourceName(url, username)); added {
+			addedRepos = append(addedRepos, repoKey)
+		}
+	}
+	return addedRepos, nil
+}
+
+// ExportMrConfig 将配置项转换为 .mrconfig 文件内容
+//
+//   - 节名称是存储库相对于存储目录的路径，因此导出的 .mrconfig 应放在存储目录中使用
+//
+// 参数：
+//   - config: 配置项
+//   - defaultSource: 存储库没有指定存储库源时使用的存储库源
+//
+// 返回：
+//   - .mrconfig 文件内容
+func ExportMrConfig(config *Config, defaultSource string) *MrConfig {
+	mrConfig := &MrConfig{}
+	sources := GetSources(config)
+	repoNames := slices.Clone(config.Git.Repos)
+	sort.Strings(repoNames)
+	for _, repoName := range repoNames {
+		source := sources[GetRepoSourceName(config, repoName, defaultSource)]
+		mrRepo := MrRepo{
+			Path: getRelativeRepoPath(config, repoName),
+			Url:  BuildSchemeRepoUrl(GetRepoScheme(config, repoName, source), source.Url, source.Username, RepoBaseName(repoName)),
+		}
+		if repoConfig, ok := config.Repo[repoName]; ok {
+			mrRepo.Branch = repoConfig.Revision
+		}
+		mrConfig.Repos = append(mrConfig.Repos, mrRepo)
+	}
+	return mrConfig
+}
+
+// gitCloneValueOptions 'git clone' 中以下一个参数为值的选项
+var gitCloneValueOptions = []string{"-o", "--origin", "-u", "--upload-pack", "-c", "--config", "-j", "--jobs", "--depth", "--reference", "--reference-if-able", "--separate-git-dir", "--template", "--shallow-since", "--shallow-exclude", "--filter", "--server-option", "--bundle-uri"}
+
+// parseGitClone 从 'git clone' 命令中解析存储库地址和分支
+//
+//   - 跳过选项及其值，'--' 之后的参数都不是选项
+//
+// 参数：
+//   - command: checkout 命令
+//
+// 返回：
+//   - 存储库地址
+//   - 分支，未指定时为空字符串
+//   - 是否是 'git clone' 命令
+func parseGitClone(command string) (string, string, bool) {
+	args := splitShellArgs(command)
+	if len(args) < 3 || args[0] != "git" || args[1] != "clone" {
+		return "", "", false
+	}
+
+	url, branch := "", ""
+	optionsEnded := false // 是否已遇到 '--'
+	for index := 2; index < len(args); index++ {
+		arg := args[index]
+		switch {
+		case optionsEnded:
+			if url == "" {
+				url = arg
+			}
+		case arg == "--":
+			optionsEnded = true
+		case arg == "-b" || arg == "--branch":
+			if index+1 < len(args) {
+				branch = args[index+1]
+				index++
+			}
+		case strings.HasPrefix(arg, "--branch="):
+			branch = strings.TrimPrefix(arg, "--branch=")
+		case slices.Contains(gitCloneValueOptions, arg):
+			index++
+		case strings.HasPrefix(arg, "-"):
+			continue
+		case url == "":
+			url = arg
+		}
+	}
+
+	return url, branch, url != ""
+}
+
+// splitShellArgs 按 shell 的规则拆分命令参数，支持单引号和双引号
+//
+// 参数：
+//   - command: 命令
+//
+// 返回：
+//   - 参数
+func splitShellArgs(command string) []string {
+	var (
+		args    []string
+		builder strings.Builder
+		quote   rune // 当前所在的引号，0 表示不在引号中
+		inArg   bool // 当前是否在参数中
+	)
+	for _, char := range command {
+		switch {
+		case quote != 0 && char == quote:
+			quote = 0
+		case quote != 0:
+			builder.WriteRune(char)
+		case char == '\'' || char == '"':
+			quote = char
+			inArg = true
+		case char == ' ' || char == '\t':
+			if inArg {
+				args = append(args, builder.String())
+				builder.Reset()
+				inArg = false
+			}
+		case char == ';' || char == '&':
+			// 只解析第一条命令
+			if inArg {
+				args = append(args, builder.String())
+			}
+			return args
+		default:
+			builder.WriteRune(char)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, builder.String())
+	}
+	return args
+}
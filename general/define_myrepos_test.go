@@ -0,0 +1,88 @@
+/*
+File: define_myrepos_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 12:04:51
+
+Description: 测试 myrepos 的 .mrconfig 文件的导入和导出
+*/
+
+package general
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// TestReadMrConfigQuoting 解析引号、续行和 'git clone' 的分支参数，忽略 DEFAULT 节和非 git 的 checkout 命令
+func TestReadMrConfigQuoting(t *testing.T) {
+	mrConfig, err := ReadMrConfig(filepath.Join("testdata", "myrepos", "quoted.mrconfig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MrRepo{
+		{Path: "src/common", Url: "git@github.com:team/common.git"},
+		{Path: "src/tools", Url: "https://gitlab.com/team/tools.git", Branch: "release 2"},
+		{Path: "lib/parser", Url: "git@github.com:team/parser.git", Branch: "dev"},
+	}
+	if !reflect.DeepEqual(mrConfig.Repos, want) {
+		t.Errorf("repos = %+v, want %+v", mrConfig.Repos, want)
+	}
+}
+
+// TestMrConfigRoundTrip 导入、导出、写入再读取后导入到新的配置项中，.mrconfig 内容和配置项都不变
+func TestMrConfigRoundTrip(t *testing.T) {
+	mrConfig, err := ReadMrConfig(filepath.Join("testdata", "myrepos", "quoted.mrconfig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &Config{Storage: StorageConfig{Path: t.TempDir()}}
+	addedRepos, err := ImportMrConfig(config, mrConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"common", "tools", "parser"}; !reflect.DeepEqual(addedRepos, want) {
+		t.Errorf("added repos = %v, want %v", addedRepos, want)
+	}
+
+	// 导出后地址、路径和分支不变，按存储库名排序
+	exported := ExportMrConfig(config, DefaultSourceName(config))
+	want := []MrRepo{
+		{Path: "src/common", Url: "git@github.com:team/common.git"},
+		{Path: "lib/parser", Url: "git@github.com:team/parser.git", Branch: "dev"},
+		{Path: "src/tools", Url: "https://gitlab.com/team/tools.git", Branch: "release 2"},
+	}
+	if !reflect.DeepEqual(exported.Repos, want) {
+		t.Errorf("exported repos = %+v, want %+v", exported.Repos, want)
+	}
+
+	// 写入后再读取得到相同的内容，导入到新的配置项中得到相同的结果
+	var buffer bytes.Buffer
+	if err := WriteMrConfig(&buffer, exported); err != nil {
+		t.Fatal(err)
+	}
+	filePath := filepath.Join(t.TempDir(), ".mrconfig")
+	if err := os.WriteFile(filePath, buffer.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reread, err := ReadMrConfig(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reread, exported) {
+		t.Errorf("reread repos = %+v, want %+v", reread.Repos, exported.Repos)
+	}
+	reimported := &Config{Storage: config.Storage}
+	if _, err := ImportMrConfig(reimported, reread); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(config.Git.Repos) // 导出时按存储库名排序
+	if !reflect.DeepEqual(reimported, config) {
+		t.Errorf("reimported config = %+v, want %+v", reimported, config)
+	}
+}
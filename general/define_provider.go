@@ -0,0 +1,558 @@
+/*
+File: define_provider.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 14:40:26
+
+Description: 调用存储库托管平台（GitHub/Gitea）的 REST API
+
+- GitHub 和 Gitea 的存储库相关接口基本一致，差异在 API 地址和分页参数
+*/
+
+package general
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	apiTimeout  = 30 * time.Second // API 请求超时时间
+	apiPageSize = 50               // API 分页大小
+)
+
+// Provider 存储库托管平台
+type Provider struct {
+	Type   string       // 平台类型，'github' 或 'gitea'
+	Api    string       // API 地址
+	Token  []byte       // 访问令牌，使用完平台对象后调用 Close 清除
+	Owner  string       // 存储库所有者（用户或组织）
+	client *http.Client // HTTP 客户端
+}
+
+// ProviderRepo 存储库托管平台上的存储库信息
+type ProviderRepo struct {
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	Private       bool   `json:"private"`
+	DefaultBranch string `json:"default_branch"`
+	Mirror        bool   `json:"mirror"`
+	Fork          bool   `json:"fork"`
+}
+
+// ProviderError API 返回的错误
+type ProviderError struct {
+	StatusCode int    // HTTP 状态码
+	Message    string // 错误信息
+}
+
+// Error 实现 error 接口
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound 判断错误是否是 404
+//
+// 参数：
+//   - err: 错误信息
+//
+// 返回：
+//   - 是 404 返回 true，否则返回 false
+func IsNotFound(err error) bool {
+	providerError, ok := err.(*ProviderError)
+	return ok && providerError.StatusCode == http.StatusNotFound
+}
+
+// NewProvider 根据存储库源配置创建存储库托管平台对象
+//
+//   - 未配置 api 时，GitHub 使用 'https://api.github.com'（GitHub Enterprise 使用 'https://<地址>/api/v3'），Gitea 使用 'https://<地址>/api/v1'
+//
+// 参数：
+//   - source: 存储库源配置
+//
+// 返回：
+//   - 存储库托管平台对象
+//   - 错误信息
+func NewProvider(source SourceConfig) (*Provider, error) {
+	provider := &Provider{
+		Type:   source.Type,
+		Api:    strings.TrimSuffix(source.Api, "/"),
+		Owner:  source.Username,
+		client: &http.Client{Timeout: apiTimeout, Transport: NewProxyTransport(source.Proxy)},
+	}
+
+	switch provider.Type {
+	case "github":
+		if provider.Api == "" {
+			if source.Url == "github.com" {
+				provider.Api = "https://api.github.com"
+			} else {
+				provider.Api = "https://" + source.Url + "/api/v3"
+			}
+		}
+	case "gitea":
+		if provider.Api == "" {
+			provider.Api = "https://" + source.Url + "/api/v1"
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported provider type '%s', optional values are 'github' and 'gitea'", provider.Type)
+	}
+
+	token, err := GetSourceToken(source)
+	if err != nil {
+		return nil, err
+	}
+	provider.Token = token
+	return provider, nil
+}
+
+// Close 清除内存中的访问令牌
+func (p *Provider) Close() {
+	ClearPassword(p.Token)
+	p.Token = nil
+}
+
+// ListRepos 列出所有者的所有存储库
+//
+//   - 所有者是组织时列出组织的存储库，所有者是令牌对应的用户时包括私有存储库
+//
+// 返回：
+//   - 存储库信息
+//   - 错误信息
+func (p *Provider) ListRepos() ([]ProviderRepo, error) {
+	// 所有者是组织
+	orgRepos, err := p.listReposPaged("/orgs/" + url.PathEscape(p.Owner) + "/repos")
+	if err == nil {
+		return filterRepos(orgRepos, ""), nil
+	}
+	if !IsNotFound(err) {
+		return nil, err
+	}
+
+	// 所有者是令牌对应的用户，使用 /user/repos 以包括私有存储库
+	if len(p.Token) > 0 {
+		login, err := p.CurrentUser()
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(login, p.Owner) {
+			userRepos, err := p.listReposPaged("/user/repos")
+			if err != nil {
+				return nil, err
+			}
+			// /user/repos 还包括用户有权限访问的其他人的存储库，需要过滤
+			return filterRepos(userRepos, p.Owner), nil
+		}
+	}
+
+	// 其他用户，只能列出公开存储库
+	publicRepos, err := p.listReposPaged("/users/" + url.PathEscape(p.Owner) + "/repos")
+	if err != nil {
+		return nil, err
+	}
+	return filterRepos(publicRepos, ""), nil
+}
+
+// GetRepo 获取所有者的指定存储库
+//
+// 参数：
+//   - name: 存储库名
+//
+// 返回：
+//   - 存储库信息
+//   - 错误信息，存储库不存在时可以用 IsNotFound 判断
+func (p *Provider) GetRepo(name string) (*ProviderRepo, error) {
+	var repo ProviderRepo
+	if err := p.request(http.MethodGet, p.repoPath(name), nil, &repo); err != nil {
+		return nil, err
+	}
+	return &repo, nil
+}
+
+// CreateRepo 为所有者创建存储库
+//
+//   - 所有者是令牌对应的用户时在用户下创建，否则在同名组织下创建
+//
+// 参数：
+//   - name: 存储库名
+//   - description: 存储库描述
+//   - private: 是否为私有存储库
+//
+// 返回：
+//   - 存储库信息
+//   - 错误信息
+func (p *Provider) CreateRepo(name, description string, private bool) (*ProviderRepo, error) {
+	login, err := p.CurrentUser()
+	if err != nil {
+		return nil, err
+	}
+	path := "/user/repos"
+	if !strings.EqualFold(login, p.Owner) {
+		path = "/orgs/" + url.PathEscape(p.Owner) + "/repos"
+	}
+
+	body := map[string]any{
+		"name":        name,
+		"description": description,
+		"private":     private,
+	}
+	var repo ProviderRepo
+	if err := p.request(http.MethodPost, path, body, &repo); err != nil {
+		return nil, err
+	}
+	return &repo, nil
+}
+
+// MigrateRepo 使用迁移 API 将其他平台的存储库迁移到所有者下，仅支持 Gitea
+//
+// 参数：
+//   - cloneAddr: 源存储库的 HTTPS 地址
+//   - service: 源平台类型，'github'、'gitea' 或 'git'
+//   - authToken: 源平台的访问令牌，用于迁移私有存储库
+//   - name: 存储库名
+//   - description: 存储库描述
+//   - private: 是否为私有存储库
+//
+// 返回：
+//   - 存储库信息
+//   - 错误信息
+func (p *Provider) MigrateRepo(cloneAddr, service string, authToken []byte, name, description string, private bool) (*ProviderRepo, error) {
+	if p.Type != "gitea" {
+		return nil, fmt.Errorf("Migration is only supported by gitea")
+	}
+	body := map[string]any{
+		"clone_addr":  cloneAddr,
+		"service":     service,
+		"auth_token":  string(authToken),
+		"repo_name":   name,
+		"repo_owner":  p.Owner,
+		"description": description,
+		"private":     private,
+		"mirror":      false,
+		"releases":    true,
+		"wiki":        true,
+	}
+	var repo ProviderRepo
+	if err := p.request(http.MethodPost, "/repos/migrate", body, &repo); err != nil {
+		return nil, err
+	}
+	return &repo, nil
+}
+
+// PushMirror Gitea 存储库的推送镜像
+type PushMirror struct {
+	RemoteName    string `json:"remote_name"`
+	RemoteAddress string `json:"remote_address"`
+	Interval      string `json:"interval"`
+	SyncOnCommit  bool   `json:"sync_on_commit"`
+	LastUpdate    string `json:"last_update"`
+	LastError     string `json:"last_error"`
+}
+
+// ListPushMirrors 列出存储库的推送镜像，仅支持 Gitea
+//
+// 参数：
+//   - name: 存储库名
+//
+// 返回：
+//   - 推送镜像
+//   - 错误信息
+func (p *Provider) ListPushMirrors(name string) ([]PushMirror, error) {
+	if p.Type != "gitea" {
+		return nil, fmt.Errorf("Push mirrors are only supported by gitea")
+	}
+	var pushMirrors []PushMirror
+	if err := p.request(http.MethodGet, p.repoPath(name)+"/push_mirrors", nil, &pushMirrors); err != nil {
+		return nil, err
+	}
+	return pushMirrors, nil
+}
+
+// CreatePushMirror 为存储库创建推送镜像，仅支持 Gitea
+//
+// 参数：
+//   - name: 存储库名
+//   - address: 推送目标存储库的 HTTPS 地址
+//   - username: 推送目标的用户名
+//   - token: 推送目标的访问令牌
+//   - interval: 定时同步间隔，例如 '8h0m0s'
+//   - syncOnCommit: 是否在推送提交时同步
+//
+// 返回：
+//   - 错误信息
+func (p *Provider) CreatePushMirror(name, address, username string, token []byte, interval string, syncOnCommit bool) error {
+	if p.Type != "gitea" {
+		return fmt.Errorf("Push mirrors are only supported by gitea")
+	}
+	body := map[string]any{
+		"remote_address":  address,
+		"remote_username": username,
+		"remote_password": string(token),
+		"interval":        interval,
+		"sync_on_commit":  syncOnCommit,
+	}
+	return p.request(http.MethodPost, p.repoPath(name)+"/push_mirrors", body, nil)
+}
+
+// DeletePushMirror 删除存储库的推送镜像，仅支持 Gitea
+//
+// 参数：
+//   - name: 存储库名
+//   - remoteName: 推送镜像的远程名称
+//
+// 返回：
+//   - 错误信息
+func (p *Provider) DeletePushMirror(name, remoteName string) error {
+	if p.Type != "gitea" {
+		return fmt.Errorf("Push mirrors are only supported by gitea")
+	}
+	return p.request(http.MethodDelete, p.repoPath(name)+"/push_mirrors/"+url.PathEscape(remoteName), nil, nil)
+}
+
+// PushMirrorKey 构建推送镜像在凭据指纹文件中的键，格式为 '<API 地址>/repos/<所有者>/<存储库名> <推送目标地址>'
+//
+// 参数：
+//   - name: 存储库名
+//   - address: 推送目标存储库的 HTTPS 地址
+//
+// 返回：
+//   - 键
+func (p *Provider) PushMirrorKey(name, address string) string {
+	return p.Api + p.repoPath(name) + " " + strings.TrimSuffix(address, ".git")
+}
+
+// PushMirrorFingerprint 计算推送镜像凭据的指纹
+//
+//   - Gitea 不返回推送镜像的凭据，只能通过创建时记录的指纹判断访问令牌是否变化
+//
+// 参数：
+//   - username: 推送目标的用户名
+//   - token: 推送目标的访问令牌
+//
+// 返回：
+//   - 指纹，格式为 'sha256:<十六进制>'
+func PushMirrorFingerprint(username string, token []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(username + "\x00"))
+	hash.Write(token)
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// PushMirrorRecord 创建推送镜像时的记录
+type PushMirrorRecord struct {
+	Fingerprint string `json:"fingerprint"`           // 凭据指纹
+	RemoteName  string `json:"remote_name,omitempty"` // 推送镜像的远程名称，为空表示未知
+}
+
+// UnmarshalJSON 兼容只记录凭据指纹字符串的旧格式
+func (r *PushMirrorRecord) UnmarshalJSON(data []byte) error {
+	var fingerprint string
+	if err := json.Unmarshal(data, &fingerprint); err == nil {
+		*r = PushMirrorRecord{Fingerprint: fingerprint}
+		return nil
+	}
+	type record PushMirrorRecord // 避免递归调用 UnmarshalJSON
+	return json.Unmarshal(data, (*record)(r))
+}
+
+// ReadPushMirrorRecords 读取推送镜像记录文件
+//
+// 参数：
+//   - filePath: 推送镜像记录文件路径
+//
+// 返回：
+//   - 推送镜像的键到记录的映射，文件不存在时为空
+//   - 错误信息
+func ReadPushMirrorRecords(filePath string) (map[string]PushMirrorRecord, error) {
+	records := make(map[string]PushMirrorRecord)
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return records, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("Parse %s: %s", filePath, err)
+	}
+	return records, nil
+}
+
+// WritePushMirrorRecords 写入推送镜像记录文件，已存在则覆盖
+//
+// 参数：
+//   - filePath: 推送镜像记录文件路径
+//   - records: 推送镜像的键到记录的映射
+//
+// 返回：
+//   - 错误信息
+func WritePushMirrorRecords(filePath string, records map[string]PushMirrorRecord) error {
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := CreateFile(filePath); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, append(data, '\n'), 0600)
+}
+
+// repoPath 构建存储库的 API 路径
+//
+// 参数：
+//   - name: 存储库名
+//
+// 返回：
+//   - API 路径
+func (p *Provider) repoPath(name string) string {
+	return "/repos/" + url.PathEscape(p.Owner) + "/" + url.PathEscape(name)
+}
+
+// CurrentUser 获取令牌对应的用户名
+//
+// 返回：
+//   - 用户名
+//   - 错误信息
+func (p *Provider) CurrentUser() (string, error) {
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := p.request(http.MethodGet, "/user", nil, &user); err != nil {
+		return "", err
+	}
+	return user.Login, nil
+}
+
+// ownedRepo 带所有者信息的存储库，用于过滤 /user/repos 的结果
+type ownedRepo struct {
+	ProviderRepo
+	Owner struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+}
+
+// ownedBy 判断存储库是否属于指定所有者
+//
+// 参数：
+//   - owner: 所有者
+//
+// 返回：
+//   - 属于返回 true，否则返回 false
+func (r ownedRepo) ownedBy(owner string) bool {
+	return strings.EqualFold(r.Owner.Login, owner)
+}
+
+// filterRepos 过滤出属于指定所有者的存储库
+//
+// 参数：
+//   - repos: 带所有者信息的存储库
+//   - owner: 所有者，为空时不过滤
+//
+// 返回：
+//   - 存储库信息
+func filterRepos(repos []ownedRepo, owner string) []ProviderRepo {
+	filteredRepos := make([]ProviderRepo, 0, len(repos))
+	for _, repo := range repos {
+		if owner == "" || repo.ownedBy(owner) {
+			filteredRepos = append(filteredRepos, repo.ProviderRepo)
+		}
+	}
+	return filteredRepos
+}
+
+// listReposPaged 分页获取存储库列表
+//
+// 参数：
+//   - path: API 路径
+//
+// 返回：
+//   - 存储库信息
+//   - 错误信息
+func (p *Provider) listReposPaged(path string) ([]ownedRepo, error) {
+	// GitHub 使用 per_page，Gitea 使用 limit
+	sizeParam := "per_page"
+	if p.Type == "gitea" {
+		sizeParam = "limit"
+	}
+
+	var repos []ownedRepo
+	for page := 1; ; page++ {
+		var pageRepos []ownedRepo
+		query := fmt.Sprintf("?%s=%d&page=%d", sizeParam, apiPageSize, page)
+		if err := p.request(http.MethodGet, path+query, nil, &pageRepos); err != nil {
+			return nil, err
+		}
+		repos = append(repos, pageRepos...)
+		if len(pageRepos) < apiPageSize {
+			break
+		}
+	}
+	return repos, nil
+}
+
+// request 发送 API 请求
+//
+// 参数：
+//   - method: HTTP 方法
+//   - path: API 路径（包括查询参数）
+//   - body: 请求体，会被编码为 JSON，为 nil 时不发送请求体
+//   - result: 用于解码响应体的对象，为 nil 时忽略响应体
+//
+// 返回：
+//   - 错误信息
+func (p *Provider) request(method, path string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequest(method, p.Api+path, reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if len(p.Token) > 0 {
+		request.Header.Set("Authorization", "token "+string(p.Token))
+	}
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		var apiError struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiError) != nil || apiError.Message == "" {
+			apiError.Message = http.StatusText(response.StatusCode)
+		}
+		return &ProviderError{StatusCode: response.StatusCode, Message: apiError.Message}
+	}
+
+	if result != nil && len(data) > 0 {
+		return json.Unmarshal(data, result)
+	}
+	return nil
+}
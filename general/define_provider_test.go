@@ -0,0 +1,297 @@
+/*
+File: define_provider_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 16:58:37
+
+Description: 使用 HTTP 替身测试 GitHub/Gitea 的 REST API 调用
+*/
+
+package general
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeApi 模拟 GitHub/Gitea API 的 HTTP 替身
+type fakeApi struct {
+	t         *testing.T
+	prefix    string                      // API 路径前缀，Gitea 为 '/api/v1'
+	sizeParam string                      // 分页大小参数名
+	token     string                      // 接受的访问令牌，为空时不校验
+	login     string                      // 令牌对应的用户名
+	repos     map[string][]map[string]any // API 路径到存储库列表的映射
+	mutex     sync.Mutex                  // 保护 requests
+	requests  []string                    // 收到的请求，格式为 '<方法> <路径>?<查询参数>'
+}
+
+// ServeHTTP 实现 http.Handler 接口
+func (f *fakeApi) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	f.mutex.Lock()
+	f.requests = append(f.requests, request.Method+" "+request.URL.RequestURI())
+	f.mutex.Unlock()
+
+	writeJson := func(status int, value any) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(status)
+		json.NewEncoder(writer).Encode(value)
+	}
+
+	// 校验访问令牌
+	authorization := request.Header.Get("Authorization")
+	if f.token != "" && authorization != "" && authorization != "token "+f.token {
+		writeJson(http.StatusUnauthorized, map[string]string{"message": "Bad credentials"})
+		return
+	}
+	authenticated := f.token != "" && authorization == "token "+f.token
+
+	path, ok := strings.CutPrefix(request.URL.Path, f.prefix)
+	if !ok {
+		writeJson(http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+	if path == "/user" {
+		if !authenticated {
+			writeJson(http.StatusUnauthorized, map[string]string{"message": "Requires authentication"})
+			return
+		}
+		writeJson(http.StatusOK, map[string]string{"login": f.login})
+		return
+	}
+	if path == "/user/repos" && !authenticated {
+		writeJson(http.StatusUnauthorized, map[string]string{"message": "Requires authentication"})
+		return
+	}
+	repos, ok := f.repos[path]
+	if !ok {
+		writeJson(http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+
+	// 分页
+	size, err := strconv.Atoi(request.URL.Query().Get(f.sizeParam))
+	if err != nil || size <= 0 {
+		f.t.Errorf("request %s: missing page size parameter %s", request.URL, f.sizeParam)
+		size = len(repos)
+	}
+	page, err := strconv.Atoi(request.URL.Query().Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+	start, end := min((page-1)*size, len(repos)), min(page*size, len(repos))
+	writeJson(http.StatusOK, repos[start:end])
+}
+
+// newFakeRepos 生成指定所有者的存储库列表，存储库名为 '<前缀>1' 到 '<前缀><数量>'
+func newFakeRepos(owner, prefix string, count int) []map[string]any {
+	repos := make([]map[string]any, 0, count)
+	for index := 1; index <= count; index++ {
+		repos = append(repos, map[string]any{
+			"name":           fmt.Sprintf("%s%d", prefix, index),
+			"default_branch": "main",
+			"owner":          map[string]string{"login": owner},
+		})
+	}
+	return repos
+}
+
+// repoNames 获取存储库名
+func repoNames(repos []ProviderRepo) []string {
+	names := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		names = append(names, repo.Name)
+	}
+	return names
+}
+
+// newTestProvider 启动 HTTP 替身并创建连接到它的存储库托管平台对象
+func newTestProvider(t *testing.T, api *fakeApi, source SourceConfig) *Provider {
+	t.Helper()
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	// 缩小分页大小以测试分页
+	originalPageSize := apiPageSize
+	apiPageSize = 2
+	t.Cleanup(func() { apiPageSize = originalPageSize })
+
+	source.Api = server.URL + api.prefix
+	source.Proxy = "direct"
+	provider, err := NewProvider(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+// TestGithubListOrgReposPaged 组织的存储库分页获取直到最后一页不满
+func TestGithubListOrgReposPaged(t *testing.T) {
+	api := &fakeApi{t: t, sizeParam: "per_page", repos: map[string][]map[string]any{
+		"/orgs/acme/repos": newFakeRepos("acme", "repo", 5),
+	}}
+	provider := newTestProvider(t, api, SourceConfig{Type: "github", Username: "acme"})
+
+	repos, err := provider.ListRepos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"repo1", "repo2", "repo3", "repo4", "repo5"}; !reflect.DeepEqual(repoNames(repos), want) {
+		t.Errorf("repos = %v, want %v", repoNames(repos), want)
+	}
+	wantRequests := []string{
+		"GET /orgs/acme/repos?per_page=2&page=1",
+		"GET /orgs/acme/repos?per_page=2&page=2",
+		"GET /orgs/acme/repos?per_page=2&page=3",
+	}
+	if !reflect.DeepEqual(api.requests, wantRequests) {
+		t.Errorf("requests = %v, want %v", api.requests, wantRequests)
+	}
+}
+
+// TestGithubListUserReposWithToken 所有者是令牌对应的用户时使用 /user/repos，并过滤掉其他所有者的存储库
+func TestGithubListUserReposWithToken(t *testing.T) {
+	userRepos := append(newFakeRepos("Alice", "own", 3), newFakeRepos("bob", "shared", 1)...)
+	api := &fakeApi{t: t, sizeParam: "per_page", token: "secret", login: "Alice", repos: map[string][]map[string]any{
+		"/user/repos":        userRepos,
+		"/users/alice/repos": newFakeRepos("Alice", "public", 1),
+	}}
+	provider := newTestProvider(t, api, SourceConfig{Type: "github", Username: "alice", Token: "secret"})
+
+	repos, err := provider.ListRepos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"own1", "own2", "own3"}; !reflect.DeepEqual(repoNames(repos), want) {
+		t.Errorf("repos = %v, want %v", repoNames(repos), want)
+	}
+	for _, request := range api.requests {
+		if strings.Contains(request, "/users/") {
+			t.Errorf("unexpected request %s, want only authenticated user repos", request)
+		}
+	}
+}
+
+// TestGithubListUserReposWithoutToken 没有令牌时只列出公开存储库，且不发送 Authorization 头
+func TestGithubListUserReposWithoutToken(t *testing.T) {
+	api := &fakeApi{t: t, sizeParam: "per_page", token: "secret", login: "alice", repos: map[string][]map[string]any{
+		"/users/alice/repos": newFakeRepos("alice", "public", 3),
+	}}
+	provider := newTestProvider(t, api, SourceConfig{Type: "github", Username: "alice"})
+
+	repos, err := provider.ListRepos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"public1", "public2", "public3"}; !reflect.DeepEqual(repoNames(repos), want) {
+		t.Errorf("repos = %v, want %v", repoNames(repos), want)
+	}
+	for _, request := range api.requests {
+		if request == "GET /user" || strings.HasPrefix(request, "GET /user/") {
+			t.Errorf("unexpected request %s without token", request)
+		}
+	}
+}
+
+// TestGiteaListReposPaged Gitea 使用 '/api/v1' 前缀和 limit 分页参数
+func TestGiteaListReposPaged(t *testing.T) {
+	api := &fakeApi{t: t, prefix: "/api/v1", sizeParam: "limit", repos: map[string][]map[string]any{
+		"/orgs/ops/repos": newFakeRepos("ops", "tool", 4),
+	}}
+	provider := newTestProvider(t, api, SourceConfig{Type: "gitea", Username: "ops"})
+
+	repos, err := provider.ListRepos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"tool1", "tool2", "tool3", "tool4"}; !reflect.DeepEqual(repoNames(repos), want) {
+		t.Errorf("repos = %v, want %v", repoNames(repos), want)
+	}
+	// 最后一页正好满时还需要再请求一页才能确定结束
+	wantRequests := []string{
+		"GET /api/v1/orgs/ops/repos?limit=2&page=1",
+		"GET /api/v1/orgs/ops/repos?limit=2&page=2",
+		"GET /api/v1/orgs/ops/repos?limit=2&page=3",
+	}
+	if !reflect.DeepEqual(api.requests, wantRequests) {
+		t.Errorf("requests = %v, want %v", api.requests, wantRequests)
+	}
+}
+
+// TestProviderBadToken 令牌无效时返回 API 的错误信息
+func TestProviderBadToken(t *testing.T) {
+	for _, providerType := range []string{"github", "gitea"} {
+		t.Run(providerType, func(t *testing.T) {
+			api := &fakeApi{t: t, sizeParam: "per_page", token: "secret", repos: map[string][]map[string]any{}}
+			if providerType == "gitea" {
+				api.prefix, api.sizeParam = "/api/v1", "limit"
+			}
+			provider := newTestProvider(t, api, SourceConfig{Type: providerType, Username: "alice", Token: "wrong"})
+
+			_, err := provider.ListRepos()
+			providerError, ok := err.(*ProviderError)
+			if !ok {
+				t.Fatalf("error = %v, want *ProviderError", err)
+			}
+			if providerError.StatusCode != http.StatusUnauthorized || providerError.Message != "Bad credentials" {
+				t.Errorf("error = %v, want 401 Bad credentials", providerError)
+			}
+		})
+	}
+}
+
+// TestNewProviderDefaultApi 未配置 api 时根据存储库源地址推断
+func TestNewProviderDefaultApi(t *testing.T) {
+	for _, testCase := range []struct {
+		source SourceConfig
+		want   string
+	}{
+		{SourceConfig{Type: "github", Url: "github.com"}, "https://api.github.com"},
+		{SourceConfig{Type: "github", Url: "ghe.example.com"}, "https://ghe.example.com/api/v3"},
+		{SourceConfig{Type: "gitea", Url: "git.example.com"}, "https://git.example.com/api/v1"},
+		{SourceConfig{Type: "gitea", Url: "git.example.com", Api: "https://api.example.com/v1/"}, "https://api.example.com/v1"},
+	} {
+		provider, err := NewProvider(testCase.source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if provider.Api != testCase.want {
+			t.Errorf("api of %+v = %s, want %s", testCase.source, provider.Api, testCase.want)
+		}
+	}
+	if _, err := NewProvider(SourceConfig{Type: "gitlab"}); err == nil {
+		t.Error("unsupported provider type accepted")
+	}
+}
+
+// TestPushMirrorRecordsLegacy 读取只记录凭据指纹字符串的旧格式，写入后为新格式
+func TestPushMirrorRecordsLegacy(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "push_mirrors.json")
+	if err := os.WriteFile(filePath, []byte(`{"old": "sha256:aa", "new": {"fingerprint": "sha256:bb", "remote_name": "remote_mirror_2"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	records, err := ReadPushMirrorRecords(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]PushMirrorRecord{"old": {Fingerprint: "sha256:aa"}, "new": {Fingerprint: "sha256:bb", RemoteName: "remote_mirror_2"}}
+	if !reflect.DeepEqual(records, want) {
+		t.Fatalf("records = %+v, want %+v", records, want)
+	}
+
+	if err := WritePushMirrorRecords(filePath, records); err != nil {
+		t.Fatal(err)
+	}
+	if reread, err := ReadPushMirrorRecords(filePath); err != nil || !reflect.DeepEqual(reread, want) {
+		t.Errorf("reread records = %+v, %v, want %+v", reread, err, want)
+	}
+}
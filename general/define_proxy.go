@@ -0,0 +1,230 @@
+/*
+File: define_proxy.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 22:26:40
+
+Description: 代理设置
+
+- 代理按以下顺序确定：存储库源的 proxy、全局的 proxy.url、环境变量 HTTPS_PROXY/ALL_PROXY（遵循 NO_PROXY），proxy 为 'direct' 时不使用代理
+- 支持 http、https（HTTP CONNECT）和 socks5 代理，SSH 传输、HTTPS 传输和 API 请求都会使用
+*/
+
+package general
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+)
+
+var DirectProxy = "direct" // 代理设置 - 不使用代理
+
+var (
+	proxyMutex          sync.Mutex                // 保护已注册的代理设置
+	defaultProxySetting string                    // 全局代理设置
+	sourceProxySettings = make(map[string]string) // 存储库源地址到其代理设置的映射
+)
+
+// InstallProxyTransports 为 go-git 的 http/https 协议安装按主机选择代理和访问令牌的 HTTP 客户端，并注册 SSH 使用的 HTTP CONNECT 代理
+func InstallProxyTransports() {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(request *http.Request) (*url.URL, error) {
+		return hostProxyUrl(request.URL.Hostname(), request.URL)
+	}
+	gitClient := &httpAuthTransport{transport: githttp.NewClient(&http.Client{Transport: transport})}
+	client.InstallProtocol("http", gitClient)
+	client.InstallProtocol("https", gitClient)
+
+	for _, scheme := range []string{"http", "https"} {
+		proxy.RegisterDialerType(scheme, func(proxyUrl *url.URL, _ proxy.Dialer) (proxy.Dialer, error) {
+			return &connectDialer{proxyUrl: proxyUrl}, nil
+		})
+	}
+}
+
+// SetProxies 注册全局和各存储库源的代理设置，供 SSH 和 HTTPS 传输按主机选择代理
+//
+// 参数：
+//   - config: 配置项
+func SetProxies(config *Config) {
+	proxyMutex.Lock()
+	defer proxyMutex.Unlock()
+
+	defaultProxySetting = config.Proxy.Url
+	sourceProxySettings = make(map[string]string)
+	for _, source := range GetSources(config) {
+		sourceProxySettings[source.Url] = source.Proxy
+	}
+}
+
+// ResolveProxy 根据代理设置获取访问目标地址使用的代理
+//
+// 参数：
+//   - setting: 代理设置，为空时使用环境变量，为 'direct' 时不使用代理
+//   - target: 目标地址
+//
+// 返回：
+//   - 代理地址，不使用代理时为 nil
+//   - 错误信息
+func ResolveProxy(setting string, target *url.URL) (*url.URL, error) {
+	switch setting {
+	case DirectProxy:
+		return nil, nil
+	case "":
+		allProxy := firstEnv("ALL_PROXY", "all_proxy")
+		proxyConfig := &httpproxy.Config{
+			HTTPProxy:  firstEnv("HTTP_PROXY", "http_proxy"),
+			HTTPSProxy: firstEnv("HTTPS_PROXY", "https_proxy"),
+			NoProxy:    firstEnv("NO_PROXY", "no_proxy"),
+		}
+		if proxyConfig.HTTPProxy == "" {
+			proxyConfig.HTTPProxy = allProxy
+		}
+		if proxyConfig.HTTPSProxy == "" {
+			proxyConfig.HTTPSProxy = allProxy
+		}
+		return proxyConfig.ProxyFunc()(target)
+	default:
+		proxyUrl, err := url.Parse(setting)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid proxy %s: %s", setting, err)
+		}
+		return proxyUrl, nil
+	}
+}
+
+// NewProxyTransport 创建使用指定代理设置的 HTTP 传输
+//
+// 参数：
+//   - setting: 代理设置，参见 ResolveProxy
+//
+// 返回：
+//   - HTTP 传输
+func NewProxyTransport(setting string) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(request *http.Request) (*url.URL, error) {
+		return ResolveProxy(setting, request.URL)
+	}
+	return transport
+}
+
+// hostProxyUrl 获取访问主机时使用的代理
+//
+// 参数：
+//   - host: 主机地址（存储库源地址）
+//   - target: 目标地址
+//
+// 返回：
+//   - 代理地址，不使用代理时为 nil
+//   - 错误信息
+func hostProxyUrl(host string, target *url.URL) (*url.URL, error) {
+	proxyMutex.Lock()
+	setting, ok := sourceProxySettings[host]
+	if !ok {
+		setting = defaultProxySetting
+	}
+	proxyMutex.Unlock()
+
+	return ResolveProxy(setting, target)
+}
+
+// firstEnv 获取第一个非空的环境变量
+//
+// 参数：
+//   - names: 环境变量名
+//
+// 返回：
+//   - 环境变量的值，都为空时为空字符串
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// connectDialer 通过 HTTP CONNECT 代理建立 TCP 连接
+type connectDialer struct {
+	proxyUrl *url.URL // 代理地址
+}
+
+func (d *connectDialer) Dial(network, addr string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, addr)
+}
+
+func (d *connectDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	proxyAddr := d.proxyUrl.Host
+	if d.proxyUrl.Port() == "" {
+		port := "80"
+		if d.proxyUrl.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(d.proxyUrl.Hostname(), port)
+	}
+
+	conn, err := (&net.Dialer{Timeout: sshTimeout}).DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if d.proxyUrl.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: d.proxyUrl.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	request := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if user := d.proxyUrl.User; user != nil {
+		password, _ := user.Password()
+		request.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user.Username()+":"+password)))
+	}
+	if err := request.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		conn.Close()
+		return nil, fmt.Errorf("Proxy %s refused to connect to %s: %s", d.proxyUrl.Redacted(), addr, response.Status)
+	}
+
+	// 代理响应之后的数据可能已被读入缓冲区
+	return &bufferedConn{Conn: conn, reader: reader}, nil
+}
+
+// bufferedConn 先从缓冲区读取数据的连接
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(data []byte) (int, error) {
+	return c.reader.Read(data)
+}
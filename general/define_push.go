@@ -0,0 +1,198 @@
+/*
+File: define_push.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 16:48:15
+
+Description: 将本地存储库的所有分支和标签推送到镜像源
+
+- 推送前先从 origin 拉取，以 origin 的分支（refs/remotes/origin/*）作为推送来源，本地分支可能落后于 origin
+- 对比推送来源和远端引用，制定推送计划，非快进的更新默认拒绝
+- 强制推送时要求远端引用仍是对比时的值，避免覆盖对比之后他人推送的提交
+*/
+
+package general
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+var (
+	PushUpToDate    = "up-to-date"   // 推送状态 - 已是最新
+	PushNew         = "new"          // 推送状态 - 远端不存在，新建
+	PushFastForward = "fast-forward" // 推送状态 - 快进更新
+	PushForced      = "forced"       // 推送状态 - 强制更新
+	PushRejected    = "rejected"     // 推送状态 - 非快进更新，已拒绝
+)
+
+// RefPush 一个引用的推送计划
+type RefPush struct {
+	Ref    plumbing.ReferenceName // 远端引用名
+	Source plumbing.ReferenceName // 推送来源的本地引用名
+	Local  string                 // 推送来源的哈希
+	Remote string                 // 远端哈希，远端不存在时为空
+	Status string                 // 推送状态
+}
+
+// FetchOriginRefs 从 origin 拉取所有分支和标签，作为推送到镜像源的来源
+//
+//   - 裸镜像存储库拉取所有引用，等同于 FetchMirrorRepo
+//   - 其他存储库只更新 origin 的远程跟踪分支（refs/remotes/origin/*）和标签，不修改本地分支和工作树
+//
+// 参数：
+//   - repo: 本地存储库对象
+//   - publicKeys: ssh 公钥
+//
+// 返回：
+//   - 错误信息
+func FetchOriginRefs(repo *git.Repository, publicKeys *ssh.PublicKeys) error {
+	fetchOptions := &git.FetchOptions{
+		Auth:       publicKeys,
+		RemoteName: remoteName,
+		RefSpecs:   []config.RefSpec{config.RefSpec("+refs/heads/*:refs/remotes/" + remoteName + "/*")},
+		Tags:       git.AllTags,
+		Prune:      true,
+		Force:      true,
+	}
+	if _, err := repo.Worktree(); err == git.ErrIsBareRepository {
+		fetchOptions.RefSpecs = []config.RefSpec{"+refs/*:refs/*"}
+		fetchOptions.Tags = git.NoTags
+	}
+	if err := repo.Fetch(fetchOptions); err != nil && err != git.NoErrAlreadyUpToDate {
+		return err
+	}
+	return nil
+}
+
+// PlanMirrorPush 对比推送来源与远端引用，制定推送计划
+//
+//   - 推送来源是 origin 的远程跟踪分支（推送到同名分支）和标签，裸镜像存储库的本地分支与 origin 一致，直接作为推送来源
+//   - 推送前应调用 FetchOriginRefs 更新推送来源
+//   - 远端分支是推送来源的祖先时为快进更新，其他情况（包括本地没有远端提交、标签指向不同对象）视为非快进更新
+//
+// 参数：
+//   - repo: 本地存储库对象
+//   - remoteRefs: 远端引用名到哈希的映射
+//   - force: 是否允许非快进更新
+//
+// 返回：
+//   - 推送计划，按引用名排序
+//   - 错误信息
+func PlanMirrorPush(repo *git.Repository, remoteRefs map[plumbing.ReferenceName]string, force bool) ([]RefPush, error) {
+	references, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = repo.Worktree()
+	isBare := err == git.ErrIsBareRepository
+	remotePrefix := "refs/remotes/" + remoteName + "/"
+
+	var plans []RefPush
+	err = references.ForEach(func(reference *plumbing.Reference) error {
+		if reference.Type() != plumbing.HashReference {
+			return nil
+		}
+		// 确定推送到的远端引用
+		var ref plumbing.ReferenceName
+		switch name := reference.Name(); {
+		case name.IsTag(), isBare && name.IsBranch():
+			ref = name
+		case !isBare && strings.HasPrefix(name.String(), remotePrefix):
+			ref = plumbing.NewBranchReferenceName(strings.TrimPrefix(name.String(), remotePrefix))
+		default:
+			return nil
+		}
+		plan := RefPush{Ref: ref, Source: reference.Name(), Local: reference.Hash().String(), Remote: remoteRefs[ref]}
+		switch {
+		case plan.Remote == "":
+			plan.Status = PushNew
+		case plan.Remote == plan.Local:
+			plan.Status = PushUpToDate
+		case ref.IsBranch() && isFastForward(repo, plumbing.NewHash(plan.Remote), reference.Hash()):
+			plan.Status = PushFastForward
+		case force:
+			plan.Status = PushForced
+		default:
+			plan.Status = PushRejected
+		}
+		plans = append(plans, plan)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(plans, func(i, j int) bool {
+		return plans[i].Ref < plans[j].Ref
+	})
+
+	return plans, nil
+}
+
+// PushMirrorRefs 按推送计划将引用推送到远端存储库，已是最新和已拒绝的引用不推送
+//
+// 参数：
+//   - repo: 本地存储库对象
+//   - URL: 远端存储库地址（仅包括主地址，例如：github.com）
+//   - username: 远端存储库用户名
+//   - repoName: 远端存储库名称
+//   - plans: 推送计划
+//   - publicKeys: ssh 公钥
+//
+// 返回：
+//   - 错误信息
+func PushMirrorRefs(repo *git.Repository, URL, username, repoName string, plans []RefPush, publicKeys *ssh.PublicKeys) error {
+	var refSpecs, requireRefs []config.RefSpec
+	for _, plan := range plans {
+		switch plan.Status {
+		case PushNew, PushFastForward:
+			refSpecs = append(refSpecs, config.RefSpec(plan.Source+":"+plan.Ref))
+		case PushForced:
+			refSpecs = append(refSpecs, config.RefSpec("+"+plan.Source+":"+plan.Ref))
+		default:
+			continue
+		}
+		if plan.Remote != "" {
+			requireRefs = append(requireRefs, config.RefSpec(plan.Remote+":"+plan.Ref.String()))
+		}
+	}
+	if len(refSpecs) == 0 {
+		return git.NoErrAlreadyUpToDate
+	}
+
+	return repo.Push(&git.PushOptions{
+		RemoteName:        remoteName,
+		RemoteURL:         BuildRepoUrl(URL, username, repoName),
+		RefSpecs:          refSpecs,
+		RequireRemoteRefs: requireRefs,
+		Auth:              publicKeys,
+	})
+}
+
+// isFastForward 判断从旧提交更新到新提交是否是快进更新
+//
+// 参数：
+//   - repo: 本地存储库对象
+//   - oldHash: 旧提交哈希
+//   - newHash: 新提交哈希
+//
+// 返回：
+//   - 是快进更新返回 true，否则返回 false
+func isFastForward(repo *git.Repository, oldHash, newHash plumbing.Hash) bool {
+	oldCommit, err := repo.CommitObject(oldHash)
+	if err != nil {
+		return false
+	}
+	newCommit, err := repo.CommitObject(newHash)
+	if err != nil {
+		return false
+	}
+	isAncestor, err := oldCommit.IsAncestor(newCommit)
+	return err == nil && isAncestor
+}
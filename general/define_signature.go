@@ -0,0 +1,298 @@
+/*
+File: define_signature.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 23:58:17
+
+Description: 提交签名校验
+
+- OpenPGP 签名使用 verify.keyring 中的公钥校验
+- SSH 签名使用 verify.allowed_signers（格式同 ssh-keygen 的 allowed_signers 文件）校验，签名者的 principals 需要匹配提交者邮箱
+*/
+
+package general
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	cssh "golang.org/x/crypto/ssh"
+)
+
+var (
+	VerifyOffMode    = "off"    // 签名校验方式 - 不校验
+	VerifyReportMode = "report" // 签名校验方式 - 只报告违规的提交
+	VerifyRejectMode = "reject" // 签名校验方式 - 存在违规的提交时回退拉取
+)
+
+var ErrUnsignedCommit = errors.New("Commit is not signed") // 提交没有签名
+
+var (
+	sshSignatureArmorStart = "-----BEGIN SSH SIGNATURE-----"
+	sshSignatureArmorEnd   = "-----END SSH SIGNATURE-----"
+	sshSignatureMagic      = [6]byte{'S', 'S', 'H', 'S', 'I', 'G'}
+	sshSignatureNamespace  = "git" // git 使用的 SSH 签名命名空间
+)
+
+// SignatureVerifier 提交签名校验器
+type SignatureVerifier struct {
+	Mode           string          // 校验方式
+	keyring        string          // ASCII armor 格式的 OpenPGP 公钥环
+	allowedSigners []allowedSigner // 允许的 SSH 签名者
+}
+
+// allowedSigner allowed_signers 文件中的一行
+type allowedSigner struct {
+	principals []string       // 签名者邮箱模式
+	namespaces []string       // 允许的命名空间，为空时不限制
+	publicKey  cssh.PublicKey // 公钥
+}
+
+// sshSignature SSH 签名（参见 OpenSSH 的 PROTOCOL.sshsig）
+type sshSignature struct {
+	Magic         [6]byte
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// sshSignedData SSH 签名的被签名数据
+type sshSignedData struct {
+	Magic         [6]byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+// NewSignatureVerifier 根据配置项创建提交签名校验器
+//
+// 参数：
+//   - config: 配置项
+//
+// 返回：
+//   - 提交签名校验器，未启用签名校验时为 nil
+//   - 错误信息
+func NewSignatureVerifier(config *Config) (*SignatureVerifier, error) {
+	switch config.Verify.Mode {
+	case "", VerifyOffMode:
+		return nil, nil
+	case VerifyReportMode, VerifyRejectMode:
+	default:
+		return nil, fmt.Errorf("Unsupported verify mode '%s', optional values are '%s', '%s' and '%s'", config.Verify.Mode, VerifyOffMode, VerifyReportMode, VerifyRejectMode)
+	}
+	if config.Verify.Keyring == "" && config.Verify.AllowedSigners == "" {
+		return nil, fmt.Errorf("Signature verification is enabled but neither verify.keyring nor verify.allowed_signers is set")
+	}
+
+	verifier := &SignatureVerifier{Mode: config.Verify.Mode}
+	if config.Verify.Keyring != "" {
+		data, err := os.ReadFile(config.Verify.Keyring)
+		if err != nil {
+			return nil, err
+		}
+		verifier.keyring = string(data)
+	}
+	if config.Verify.AllowedSigners != "" {
+		allowedSigners, err := readAllowedSigners(config.Verify.AllowedSigners)
+		if err != nil {
+			return nil, err
+		}
+		verifier.allowedSigners = allowedSigners
+	}
+	return verifier, nil
+}
+
+// Verify 校验提交的签名
+//
+// 参数：
+//   - commit: 提交
+//
+// 返回：
+//   - 签名者
+//   - 错误信息，提交没有签名时为 ErrUnsignedCommit，签名无效或签名者不被信任时返回
+func (v *SignatureVerifier) Verify(commit *object.Commit) (string, error) {
+	signature := strings.TrimSpace(commit.PGPSignature)
+	if signature == "" {
+		return "", ErrUnsignedCommit
+	}
+
+	if strings.HasPrefix(signature, sshSignatureArmorStart) {
+		if len(v.allowedSigners) == 0 {
+			return "", fmt.Errorf("Commit is signed with SSH key but verify.allowed_signers is not set")
+		}
+		return v.verifySSH(commit, signature)
+	}
+
+	if v.keyring == "" {
+		return "", fmt.Errorf("Commit is signed with OpenPGP key but verify.keyring is not set")
+	}
+	entity, err := commit.Verify(v.keyring)
+	if err != nil {
+		return "", fmt.Errorf("Invalid OpenPGP signature or unknown key: %s", err)
+	}
+	for name := range entity.Identities {
+		return name, nil
+	}
+	return entity.PrimaryKey.KeyIdString(), nil
+}
+
+// verifySSH 校验提交的 SSH 签名
+//
+// 参数：
+//   - commit: 提交
+//   - armoredSignature: ASCII armor 格式的 SSH 签名
+//
+// 返回：
+//   - 签名者
+//   - 错误信息
+func (v *SignatureVerifier) verifySSH(commit *object.Commit, armoredSignature string) (string, error) {
+	body := strings.TrimSuffix(strings.TrimPrefix(armoredSignature, sshSignatureArmorStart), sshSignatureArmorEnd)
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(body), ""))
+	if err != nil {
+		return "", fmt.Errorf("Invalid SSH signature: %s", err)
+	}
+	var signature sshSignature
+	if err := cssh.Unmarshal(blob, &signature); err != nil {
+		return "", fmt.Errorf("Invalid SSH signature: %s", err)
+	}
+	if signature.Magic != sshSignatureMagic || signature.Version != 1 {
+		return "", fmt.Errorf("Invalid SSH signature: unsupported format")
+	}
+	if signature.Namespace != sshSignatureNamespace {
+		return "", fmt.Errorf("SSH signature namespace is '%s', expected '%s'", signature.Namespace, sshSignatureNamespace)
+	}
+	publicKey, err := cssh.ParsePublicKey(signature.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("Invalid SSH signature: %s", err)
+	}
+
+	// 计算不含签名的提交内容的摘要
+	encoded := &plumbing.MemoryObject{}
+	if err := commit.EncodeWithoutSignature(encoded); err != nil {
+		return "", err
+	}
+	reader, err := encoded.Reader()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	message, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	var hash []byte
+	switch signature.HashAlgorithm {
+	case "sha256":
+		sum := sha256.Sum256(message)
+		hash = sum[:]
+	case "sha512":
+		sum := sha512.Sum512(message)
+		hash = sum[:]
+	default:
+		return "", fmt.Errorf("Unsupported SSH signature hash algorithm '%s'", signature.HashAlgorithm)
+	}
+
+	sshSig := &cssh.Signature{}
+	if err := cssh.Unmarshal(signature.Signature, sshSig); err != nil {
+		return "", fmt.Errorf("Invalid SSH signature: %s", err)
+	}
+	signedData := cssh.Marshal(sshSignedData{
+		Magic:         sshSignatureMagic,
+		Namespace:     signature.Namespace,
+		Reserved:      signature.Reserved,
+		HashAlgorithm: signature.HashAlgorithm,
+		Hash:          hash,
+	})
+	if err := publicKey.Verify(signedData, sshSig); err != nil {
+		return "", fmt.Errorf("Invalid SSH signature: %s", err)
+	}
+
+	// 签名有效，检查签名者是否被信任
+	fingerprint := cssh.FingerprintSHA256(publicKey)
+	for _, signer := range v.allowedSigners {
+		if !bytes.Equal(signer.publicKey.Marshal(), publicKey.Marshal()) {
+			continue
+		}
+		if len(signer.namespaces) > 0 && !matchPatterns(signer.namespaces, sshSignatureNamespace) {
+			continue
+		}
+		if matchPatterns(signer.principals, commit.Committer.Email) {
+			return commit.Committer.Email, nil
+		}
+	}
+	return "", fmt.Errorf("Signed by untrusted key %s %s", publicKey.Type(), fingerprint)
+}
+
+// readAllowedSigners 读取 allowed_signers 文件
+//
+//   - 每行格式为 '<principals> [options] <keytype> <key> [comment]'，支持 namespaces 选项
+//
+// 参数：
+//   - filePath: allowed_signers 文件路径
+//
+// 返回：
+//   - 允许的 SSH 签名者
+//   - 错误信息
+func readAllowedSigners(filePath string) ([]allowedSigner, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var allowedSigners []allowedSigner
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		principals, rest, _ := strings.Cut(line, " ")
+		publicKey, _, options, _, err := cssh.ParseAuthorizedKey([]byte(strings.TrimSpace(rest)))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", filePath, lineNo, err)
+		}
+		signer := allowedSigner{principals: strings.Split(principals, ","), publicKey: publicKey}
+		for _, option := range options {
+			if value, ok := strings.CutPrefix(option, "namespaces="); ok {
+				signer.namespaces = strings.Split(strings.Trim(value, `"`), ",")
+			}
+		}
+		allowedSigners = append(allowedSigners, signer)
+	}
+	return allowedSigners, scanner.Err()
+}
+
+// matchPatterns 检测字符串是否匹配任一通配符模式
+//
+// 参数：
+//   - patterns: 通配符模式，支持 '*' 和 '?'
+//   - value: 待检测字符串
+//
+// 返回：
+//   - 匹配返回 true，否则返回 false
+func matchPatterns(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, value); matched {
+			return true
+		}
+	}
+	return false
+}
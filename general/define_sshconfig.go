@@ -0,0 +1,395 @@
+/*
+File: define_sshconfig.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 21:02:17
+
+Description: 读取 ~/.ssh/config 并应用到 SSH 传输
+
+- 为 go-git 的 ssh 协议安装一层包装，建立连接前按存储库地址中的主机名查找 ssh_config，应用 HostName、Port、User、IdentityFile 和 ProxyJump
+- ProxyJump 通过注册到 golang.org/x/net/proxy 的 'ssh' 代理实现，go-git 连接目标主机时经由跳板主机转发，配置的代理（参见 define_proxy.go）用于连接第一个跳板主机
+- 代理只能从地址创建，因此每次连接的认证方式以一次性编号登记，由代理地址中的编号取回，不同连接之间互不影响
+*/
+
+package general
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/kevinburke/ssh_config"
+	cssh "golang.org/x/crypto/ssh"
+	"golang.org/x/net/proxy"
+)
+
+// sshConfigGetter 按主机名查找 ssh_config 配置项
+type sshConfigGetter interface {
+	Get(alias, key string) string
+	GetAll(alias, key string) []string
+}
+
+var sshConfigSettings sshConfigGetter = ssh_config.DefaultUserSettings // 用户和系统的 ssh_config
+
+var (
+	jumpAuths       sync.Map      // 等待 ProxyJump 代理取用的认证方式，键为代理地址中的 auth 参数
+	jumpAuthID      atomic.Uint64 // 认证方式的登记编号
+	jumpProxyScheme = "ssh"       // ProxyJump 代理的协议名
+)
+
+// SSHHost 从 ssh_config 解析出的主机连接参数
+type SSHHost struct {
+	Alias          string   // 存储库地址中的主机名
+	HostName       string   // 实际连接的主机地址
+	Port           int      // 端口
+	User           string   // 用户名，未配置时为空字符串
+	IdentityFiles  []string // 存在的私钥文件
+	IdentitiesOnly bool     // 是否只使用 IdentityFile 指定的私钥
+	ProxyJump      []string // 跳板主机，按连接顺序排列
+}
+
+// ResolveSSHHost 查找 ssh_config 中主机的连接参数
+//
+// 参数：
+//   - alias: 主机名（存储库源地址）
+//
+// 返回：
+//   - 主机连接参数
+func ResolveSSHHost(alias string) SSHHost {
+	host := SSHHost{Alias: alias, HostName: alias, Port: sshPort}
+	if hostName := sshConfigSettings.Get(alias, "HostName"); hostName != "" {
+		host.HostName = strings.ReplaceAll(hostName, "%h", alias)
+	}
+	if port, err := strconv.Atoi(sshConfigSettings.Get(alias, "Port")); err == nil && port > 0 {
+		host.Port = port
+	}
+	host.User = sshConfigSettings.Get(alias, "User")
+	host.IdentitiesOnly = strings.EqualFold(sshConfigSettings.Get(alias, "IdentitiesOnly"), "yes")
+	for _, identityFile := range sshConfigSettings.GetAll(alias, "IdentityFile") {
+		identityFile = expandSSHPath(identityFile, host)
+		if FileExist(identityFile) {
+			host.IdentityFiles = append(host.IdentityFiles, identityFile)
+		}
+	}
+	if proxyJump := sshConfigSettings.Get(alias, "ProxyJump"); proxyJump != "" && !strings.EqualFold(proxyJump, "none") {
+		host.ProxyJump = strings.Split(proxyJump, ",")
+	}
+	return host
+}
+
+// Address 获取主机的连接地址
+//
+// 返回：
+//   - 格式为 '主机:端口' 的连接地址
+func (host SSHHost) Address() string {
+	return net.JoinHostPort(host.HostName, strconv.Itoa(host.Port))
+}
+
+// InstallSSHConfigTransport 为 go-git 的 ssh 协议安装应用 ssh_config 的包装
+//
+//   - 主机地址的解析改由本包完成，因此关闭 go-git 自身对 ssh_config 的读取
+func InstallSSHConfigTransport() {
+	ssh.DefaultSSHConfig = nil
+	client.InstallProtocol("ssh", &sshConfigTransport{transport: ssh.DefaultClient})
+	proxy.RegisterDialerType(jumpProxyScheme, func(proxyUrl *url.URL, _ proxy.Dialer) (proxy.Dialer, error) {
+		publicKeys, ok := jumpAuths.LoadAndDelete(proxyUrl.Query().Get("auth"))
+		if !ok {
+			return nil, errors.New("No SSH authentication registered for ProxyJump " + proxyUrl.Query().Get("hosts"))
+		}
+		dialer := &jumpDialer{jumps: strings.Split(proxyUrl.Query().Get("hosts"), ","), auth: publicKeys.(*ssh.PublicKeys)}
+		if forwardProxy := proxyUrl.Query().Get("proxy"); forwardProxy != "" {
+			forwardUrl, err := url.Parse(forwardProxy)
+			if err != nil {
+				return nil, err
+			}
+			if dialer.forward, err = proxy.FromURL(forwardUrl, proxy.Direct); err != nil {
+				return nil, err
+			}
+		}
+		return dialer, nil
+	})
+}
+
+// sshConfigTransport 建立 SSH 会话前应用 ssh_config 的传输
+type sshConfigTransport struct {
+	transport transport.Transport
+}
+
+func (t *sshConfigTransport) NewUploadPackSession(endpoint *transport.Endpoint, auth transport.AuthMethod) (transport.UploadPackSession, error) {
+	endpoint, auth, err := applySSHConfig(endpoint, auth)
+	if err != nil {
+		return nil, err
+	}
+	return t.transport.NewUploadPackSession(endpoint, auth)
+}
+
+func (t *sshConfigTransport) NewReceivePackSession(endpoint *transport.Endpoint, auth transport.AuthMethod) (transport.ReceivePackSession, error) {
+	endpoint, auth, err := applySSHConfig(endpoint, auth)
+	if err != nil {
+		return nil, err
+	}
+	return t.transport.NewReceivePackSession(endpoint, auth)
+}
+
+// applySSHConfig 按 ssh_config 修改连接地址和认证方式
+//
+//   - 先尝试 IdentityFile 指定的私钥，再尝试配置文件中的私钥（IdentitiesOnly 为 yes 时不尝试）
+//   - 存储库地址中的用户名为空或是构建地址时填入的默认用户名 git 时使用 ssh_config 的 User，否则地址中的用户名优先，都没有时使用认证方式中的用户名
+//
+// 参数：
+//   - endpoint: 存储库地址
+//   - auth: 认证方式
+//
+// 返回：
+//   - 修改后的存储库地址
+//   - 修改后的认证方式
+//   - 错误信息
+func applySSHConfig(endpoint *transport.Endpoint, auth transport.AuthMethod) (*transport.Endpoint, transport.AuthMethod, error) {
+	publicKeys, ok := auth.(*ssh.PublicKeys)
+	if !ok {
+		return endpoint, auth, nil
+	}
+	host := ResolveSSHHost(endpoint.Host)
+
+	signers, err := hostSigners(host, publicKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resolvedEndpoint := *endpoint
+	resolvedEndpoint.Host = host.HostName
+	resolvedEndpoint.Port = host.Port
+	if resolvedEndpoint.Proxy.URL == "" {
+		proxyUrl, err := sshProxyUrl(host, publicKeys)
+		if err != nil {
+			return nil, nil, err
+		}
+		resolvedEndpoint.Proxy = transport.ProxyOptions{URL: proxyUrl}
+	}
+	user := endpoint.User
+	if host.User != "" && (user == "" || user == defaultSSHUser) {
+		user = host.User
+	}
+	if user == "" {
+		user = publicKeys.User
+	}
+	return &resolvedEndpoint, &ssh.PublicKeysCallback{
+		User:                  user,
+		Callback:              func() ([]cssh.Signer, error) { return signers, nil },
+		HostKeyCallbackHelper: publicKeys.HostKeyCallbackHelper,
+	}, nil
+}
+
+// sshProxyUrl 获取连接主机使用的代理
+//
+//   - 配置了 ProxyJump 时经由跳板主机连接，此时代理用于连接第一个跳板主机，认证方式登记后由跳板代理取用
+//
+// 参数：
+//   - host: 主机连接参数
+//   - publicKeys: 连接跳板主机使用的认证方式
+//
+// 返回：
+//   - 代理地址，不使用代理时为空字符串
+//   - 错误信息
+func sshProxyUrl(host SSHHost, publicKeys *ssh.PublicKeys) (string, error) {
+	target := host.HostName
+	if len(host.ProxyJump) > 0 {
+		target = host.ProxyJump[0][strings.LastIndex(host.ProxyJump[0], "@")+1:]
+	}
+	proxyUrl, err := hostProxyUrl(host.Alias, &url.URL{Scheme: "https", Host: target})
+	if err != nil {
+		return "", err
+	}
+	if len(host.ProxyJump) == 0 {
+		if proxyUrl == nil {
+			return "", nil
+		}
+		return proxyUrl.String(), nil
+	}
+
+	authID := strconv.FormatUint(jumpAuthID.Add(1), 10)
+	jumpAuths.Store(authID, publicKeys)
+	query := url.Values{"hosts": {strings.Join(host.ProxyJump, ",")}, "auth": {authID}}
+	if proxyUrl != nil {
+		query.Set("proxy", proxyUrl.String())
+	}
+	return jumpProxyScheme + "://proxyjump/?" + query.Encode(), nil
+}
+
+// hostSigners 获取连接主机时依次尝试的签名器
+//
+//   - 依次为 ssh_config 的 IdentityFile、存储库源的 identity_files 和 ssh.identity_files（IdentitiesOnly 为 yes 时只使用 IdentityFile）
+//
+// 参数：
+//   - host: 主机连接参数
+//   - publicKeys: 默认认证方式，没有通过 GetSSHAuth 注册私钥时使用其中的私钥
+//
+// 返回：
+//   - 签名器
+//   - 错误信息，没有任何可用的私钥时返回
+func hostSigners(host SSHHost, publicKeys *ssh.PublicKeys) ([]cssh.Signer, error) {
+	signers, err := newIdentitySigners(host.IdentityFiles)
+	if err != nil {
+		return nil, err
+	}
+	if !host.IdentitiesOnly || len(signers) == 0 {
+		authMutex.Lock()
+		signers = append(signers, sourceSigners[host.Alias]...)
+		signers = append(signers, defaultSigners...)
+		if len(defaultSigners) == 0 && publicKeys != nil && publicKeys.Signer != nil {
+			signers = append(signers, publicKeys.Signer)
+		}
+		authMutex.Unlock()
+	}
+	if len(signers) == 0 {
+		return nil, errors.New("No usable SSH key for " + host.Alias)
+	}
+	return signers, nil
+}
+
+// jumpDialer 经由跳板主机连接目标主机的代理
+type jumpDialer struct {
+	jumps   []string        // 跳板主机，格式为 '[用户名@]主机[:端口]'
+	forward proxy.Dialer    // 连接第一个跳板主机使用的代理，为 nil 时直接连接
+	auth    *ssh.PublicKeys // 连接跳板主机使用的认证方式
+}
+
+func (d *jumpDialer) Dial(network, addr string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, addr)
+}
+
+func (d *jumpDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	var (
+		clients []*cssh.Client
+		conn    net.Conn
+		err     error
+	)
+	closeAll := func() {
+		for index := len(clients) - 1; index >= 0; index-- {
+			clients[index].Close()
+		}
+	}
+	for _, jump := range d.jumps {
+		host, clientConfig, err := jumpClientConfig(jump, d.auth)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		if len(clients) == 0 {
+			if contextDialer, ok := d.forward.(proxy.ContextDialer); ok {
+				conn, err = contextDialer.DialContext(ctx, "tcp", host.Address())
+			} else {
+				conn, err = (&net.Dialer{Timeout: sshTimeout}).DialContext(ctx, "tcp", host.Address())
+			}
+		} else {
+			conn, err = clients[len(clients)-1].Dial("tcp", host.Address())
+		}
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		clientConn, channels, requests, err := cssh.NewClientConn(conn, host.Address(), clientConfig)
+		if err != nil {
+			conn.Close()
+			closeAll()
+			return nil, err
+		}
+		clients = append(clients, cssh.NewClient(clientConn, channels, requests))
+	}
+
+	if conn, err = clients[len(clients)-1].Dial(network, addr); err != nil {
+		closeAll()
+		return nil, err
+	}
+	return &jumpConn{Conn: conn, closeAll: closeAll}, nil
+}
+
+// jumpClientConfig 获取连接跳板主机的参数
+//
+// 参数：
+//   - jump: 跳板主机，格式为 '[用户名@]主机[:端口]'
+//   - publicKeys: 默认认证方式
+//
+// 返回：
+//   - 跳板主机连接参数
+//   - ssh 客户端配置
+//   - 错误信息
+func jumpClientConfig(jump string, publicKeys *ssh.PublicKeys) (SSHHost, *cssh.ClientConfig, error) {
+	user, hostPort, found := strings.Cut(strings.TrimSpace(jump), "@")
+	if !found {
+		user, hostPort = "", user
+	}
+	alias, port := hostPort, ""
+	if splitHost, splitPort, err := net.SplitHostPort(hostPort); err == nil {
+		alias, port = splitHost, splitPort
+	}
+
+	host := ResolveSSHHost(alias)
+	if port != "" {
+		if portNumber, err := strconv.Atoi(port); err == nil {
+			host.Port = portNumber
+		}
+	}
+	if user == "" {
+		user = host.User
+	}
+	if user == "" {
+		user = UserInfo.Username
+	}
+
+	signers, err := hostSigners(host, publicKeys)
+	if err != nil {
+		return host, nil, err
+	}
+	hostKeyCallback := publicKeys.HostKeyCallback
+	if hostKeyCallback == nil {
+		if hostKeyCallback, err = ssh.NewKnownHostsCallback(); err != nil {
+			return host, nil, err
+		}
+	}
+	return host, &cssh.ClientConfig{
+		User:            user,
+		Auth:            []cssh.AuthMethod{cssh.PublicKeys(signers...)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         sshTimeout,
+	}, nil
+}
+
+// jumpConn 经由跳板主机的连接，关闭时一并关闭跳板主机的连接
+type jumpConn struct {
+	net.Conn
+	closeAll func()
+}
+
+func (c *jumpConn) Close() error {
+	err := c.Conn.Close()
+	c.closeAll()
+	return err
+}
+
+// expandSSHPath 展开 ssh_config 路径中的 '~' 和常用占位符
+//
+// 参数：
+//   - path: 路径
+//   - host: 主机连接参数
+//
+// 返回：
+//   - 展开后的路径
+func expandSSHPath(path string, host SSHHost) string {
+	homeDir, _ := os.UserHomeDir()
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		path = filepath.Join(homeDir, path[1:])
+	}
+	replacer := strings.NewReplacer("%d", homeDir, "%h", host.HostName, "%r", host.User, "%u", UserInfo.Username, "%%", "%")
+	return replacer.Replace(path)
+}
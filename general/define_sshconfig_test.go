@@ -0,0 +1,92 @@
+/*
+File: define_sshconfig_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 11:05:48
+
+Description: 测试 ssh_config 对 SSH 连接地址和用户名的应用
+*/
+
+package general
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/kevinburke/ssh_config"
+	cssh "golang.org/x/crypto/ssh"
+)
+
+// testSSHConfig 从字符串解析的 ssh_config
+type testSSHConfig struct {
+	config *ssh_config.Config
+}
+
+func (c testSSHConfig) Get(alias, key string) string {
+	value, _ := c.config.Get(alias, key)
+	return value
+}
+
+func (c testSSHConfig) GetAll(alias, key string) []string {
+	values, _ := c.config.GetAll(alias, key)
+	return values
+}
+
+// useTestSSHConfig 在测试期间使用给定内容的 ssh_config
+func useTestSSHConfig(t *testing.T, content string) {
+	config, err := ssh_config.Decode(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	originalSettings := sshConfigSettings
+	sshConfigSettings = testSSHConfig{config: config}
+	t.Cleanup(func() { sshConfigSettings = originalSettings })
+}
+
+// newTestPublicKeys 创建使用随机 ed25519 私钥的认证方式
+func newTestPublicKeys(t *testing.T) *ssh.PublicKeys {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := cssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &ssh.PublicKeys{User: "git", Signer: signer}
+}
+
+// TestApplySSHConfigUser ssh_config 的 User 覆盖构建地址时填入的 git，地址中的其他用户名优先
+func TestApplySSHConfigUser(t *testing.T) {
+	useTestSSHConfig(t, "Host work.example.com\n  HostName 10.0.0.8\n  Port 2222\n  User gitea\n")
+
+	tests := []struct {
+		rawUrl   string
+		wantUser string
+	}{
+		{BuildRepoUrl("work.example.com", "ops", "tools"), "gitea"},
+		{"ssh://work.example.com/ops/tools.git", "gitea"},
+		{"ssh://deploy@work.example.com/ops/tools.git", "deploy"},
+		{BuildRepoUrl("github.com", "ops", "tools"), "git"},
+	}
+	for _, test := range tests {
+		endpoint, err := transport.NewEndpoint(test.rawUrl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resolvedEndpoint, auth, err := applySSHConfig(endpoint, newTestPublicKeys(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user := auth.(*ssh.PublicKeysCallback).User; user != test.wantUser {
+			t.Errorf("user of %s = %q, want %q", test.rawUrl, user, test.wantUser)
+		}
+		if endpoint.Host == "work.example.com" && (resolvedEndpoint.Host != "10.0.0.8" || resolvedEndpoint.Port != 2222) {
+			t.Errorf("endpoint of %s = %s:%d, want 10.0.0.8:2222", test.rawUrl, resolvedEndpoint.Host, resolvedEndpoint.Port)
+		}
+	}
+}
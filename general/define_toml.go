@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/pelletier/go-toml"
@@ -20,10 +21,18 @@ import (
 
 // 用于转换 Toml 配置树的结构体
 type Config struct {
-	Git     GitConfig     `toml:"git"`
-	Script  ScriptConfig  `toml:"script"`
-	SSH     SSHConfig     `toml:"ssh"`
-	Storage StorageConfig `toml:"storage"`
+	Cache   CacheConfig             `toml:"cache"`
+	Git     GitConfig               `toml:"git"`
+	Proxy   ProxyConfig             `toml:"proxy,omitempty"`
+	Repo    map[string]RepoConfig   `toml:"repo,omitempty"`
+	Script  ScriptConfig            `toml:"script"`
+	Source  map[string]SourceConfig `toml:"source,omitempty"`
+	SSH     SSHConfig               `toml:"ssh"`
+	Storage StorageConfig           `toml:"storage"`
+	Verify  VerifyConfig            `toml:"verify,omitempty"`
+}
+type CacheConfig struct {
+	Path string `toml:"path"`
 }
 type GitConfig struct {
 	GithubUrl      string   `toml:"github_url"`
@@ -32,14 +41,68 @@ type GitConfig struct {
 	GiteaUsername  string   `toml:"gitea_username"`
 	Repos          []string `toml:"repos"`
 }
+type ProxyConfig struct {
+	Url string `toml:"url,omitempty"`
+}
+type RepoConfig struct {
+	Source   string `toml:"source,omitempty"`
+	Path     string `toml:"path,omitempty"`
+	Revision string `toml:"revision,omitempty"`
+	Group    string `toml:"group,omitempty"`
+	Scheme   string `toml:"scheme,omitempty"`
+}
 type ScriptConfig struct {
 	RunQueue []string `toml:"run_queue"`
 }
+type SourceConfig struct {
+	Name          string   `toml:"-"`
+	Url           string   `toml:"url"`
+	Username      string   `toml:"username"`
+	Type          string   `toml:"type,omitempty"`
+	Api           string   `toml:"api,omitempty"`
+	Token         string   `toml:"token,omitempty"`
+	HostKeys      []string `toml:"host_keys,omitempty"`
+	IdentityFiles []string `toml:"identity_files,omitempty"`
+	Proxy         string   `toml:"proxy,omitempty"`
+	Scheme        string   `toml:"scheme,omitempty"`
+}
 type SSHConfig struct {
-	RsaFile string `toml:"rsa_file"`
+	IdentityFiles []string `toml:"identity_files,omitempty"`
+	RsaFile       string   `toml:"rsa_file,omitempty"`
+	HostKeyMode   string   `toml:"host_key_mode,omitempty"`
 }
 type StorageConfig struct {
-	Path string `toml:"path"`
+	Path   string `toml:"path"`
+	Mode   string `toml:"mode"`
+	Layout string `toml:"layout,omitempty"`
+}
+
+type VerifyConfig struct {
+	Mode           string `toml:"mode,omitempty"`
+	Keyring        string `toml:"keyring,omitempty"`
+	AllowedSigners string `toml:"allowed_signers,omitempty"`
+}
+
+var (
+	WorktreeMode = "worktree" // 存储模式 - 带工作树的普通存储库
+	MirrorMode   = "mirror"   // 存储模式 - 裸镜像存储库
+)
+
+var DefaultLayout = "{name}" // 默认存储布局
+
+// CheckStorageMode 检测存储模式（storage.mode）是否有效，为空时视为工作树模式
+//
+// 参数：
+//   - mode: 存储模式
+//
+// 返回：
+//   - 错误信息
+func CheckStorageMode(mode string) error {
+	switch mode {
+	case "", WorktreeMode, MirrorMode:
+		return nil
+	}
+	return fmt.Errorf("Unsupported storage.mode '%s', optional values are '%s' and '%s'", mode, WorktreeMode, MirrorMode)
 }
 
 // isTomlFile 检测文件是不是 toml 文件
@@ -94,6 +157,375 @@ func LoadConfigToStruct(configTree *toml.Tree) (*Config, error) {
 	return &config, nil
 }
 
+// GetSources 获取所有存储库源，包括 [git] 中的 github、gitea 以及 [source] 中自定义的存储库源
+//
+//   - [source.github] 和 [source.gitea] 中非空的配置项会覆盖 [git] 中的对应配置，可用于补充 type、api、token 等配置
+//   - github 和 gitea 未指定 type 时分别默认为 'github' 和 'gitea'
+//   - 未指定 proxy 的存储库源使用全局的 proxy.url
+//
+// 参数：
+//   - config: 配置项
+//
+// 返回：
+//   - 存储库源名称到存储库源配置的映射
+func GetSources(config *Config) map[string]SourceConfig {
+	sources := make(map[string]SourceConfig)
+	if config.Git.GithubUrl != "" {
+		sources["github"] = SourceConfig{Url: config.Git.GithubUrl, Username: config.Git.GithubUsername, Type: "github"}
+	}
+	if config.Git.GiteaUrl != "" {
+		sources["gitea"] = SourceConfig{Url: config.Git.GiteaUrl, Username: config.Git.GiteaUsername, Type: "gitea"}
+	}
+	for name, source := range config.Source {
+		mergedSource := sources[name]
+		if source.Url != "" {
+			mergedSource.Url = source.Url
+		}
+		if source.Username != "" {
+			mergedSource.Username = source.Username
+		}
+		if source.Type != "" {
+			mergedSource.Type = source.Type
+		}
+		if source.Api != "" {
+			mergedSource.Api = source.Api
+		}
+		if source.Token != "" {
+			mergedSource.Token = source.Token
+		}
+		if len(source.HostKeys) > 0 {
+			mergedSource.HostKeys = source.HostKeys
+		}
+		if len(source.IdentityFiles) > 0 {
+			mergedSource.IdentityFiles = source.IdentityFiles
+		}
+		if source.Proxy != "" {
+			mergedSource.Proxy = source.Proxy
+		}
+		if source.Scheme != "" {
+			mergedSource.Scheme = source.Scheme
+		}
+		sources[name] = mergedSource
+	}
+	for name, source := range sources {
+		source.Name = name
+		if source.Proxy == "" {
+			source.Proxy = config.Proxy.Url
+		}
+		sources[name] = source
+	}
+	return sources
+}
+
+// GetMirrorSourceNames 获取存储库源的镜像源名称
+//
+//   - github 和 gitea 互为镜像源，其他存储库源没有镜像源
+//
+// 参数：
+//   - config: 配置项
+//   - source: 存储库源名称
+//
+// 返回：
+//   - 已配置的镜像源名称
+func GetMirrorSourceNames(config *Config, source string) []string {
+	var mirrorSources []string
+	sources := GetSources(config)
+	for _, mirrorSource := range map[string][]string{"github": {"gitea"}, "gitea": {"github"}}[source] {
+		if _, ok := sources[mirrorSource]; ok {
+			mirrorSources = append(mirrorSources, mirrorSource)
+		}
+	}
+	return mirrorSources
+}
+
+// GetRepoPath 获取存储库的本地路径
+//
+//   - [repo.<存储库名>] 中配置了 path 时使用该路径（相对路径基于存储目录），否则按存储布局（storage.layout）展开
+//
+// 参数：
+//   - config: 配置项
+//   - repoName: 存储库名
+//
+// 返回：
+//   - 本地存储库路径
+func GetRepoPath(config *Config, repoName string) string {
+	if repoConfig, ok := config.Repo[repoName]; ok && repoConfig.Path != "" {
+		if filepath.IsAbs(repoConfig.Path) {
+			return repoConfig.Path
+		}
+		return filepath.Join(config.Storage.Path, repoConfig.Path)
+	}
+	return filepath.Join(config.Storage.Path, ExpandLayout(config, config.Storage.Layout, repoName))
+}
+
+// ExpandLayout 展开存储布局模板，得到存储库相对于存储目录的路径
+//
+//   - 支持的占位符：{source} 存储库源名称、{host} 存储库源地址、{owner} 存储库源用户名、{group} [repo.<存储库名>] 中的 group、{name} 存储库名
+//   - 存储库键包含所有者或主机地址且模板中没有能区分它们的占位符时，{name} 展开为包含它们的路径，避免与同名存储库冲突
+//   - 没有单独指定存储库源的存储库使用默认存储库源，保证 github 和 gitea 互为镜像时路径不随 '--source' 变化
+//   - 模板为空时使用 '{name}'，展开后为空的路径段会被忽略
+//
+// 参数：
+//   - config: 配置项
+//   - layout: 存储布局模板
+//   - repoName: 存储库键
+//
+// 返回：
+//   - 相对路径
+func ExpandLayout(config *Config, layout, repoName string) string {
+	if layout == "" {
+		layout = DefaultLayout
+	}
+	sourceName := GetRepoSourceName(config, repoName, DefaultSourceName(config))
+	source := GetSources(config)[sourceName]
+	replacer := strings.NewReplacer(
+		"{source}", sourceName,
+		"{host}", source.Url,
+		"{owner}", source.Username,
+		"{group}", config.Repo[repoName].Group,
+		"{name}", layoutName(layout, repoName),
+	)
+	return filepath.Join(strings.Split(replacer.Replace(layout), "/")...)
+}
+
+// layoutName 获取存储布局中 {name} 展开后的值
+//
+//   - 存储库键中的主机地址和所有者用于区分同名存储库，存储布局中已有能区分它们的占位符时省略，否则作为路径前缀保留
+//
+// 参数：
+//   - layout: 存储布局模板
+//   - repoKey: 存储库键
+//
+// 返回：
+//   - {name} 展开后的值
+func layoutName(layout, repoKey string) string {
+	host, owner, name := splitRepoKey(repoKey)
+	var parts []string
+	if host != "" && !strings.Contains(layout, "{source}") && !strings.Contains(layout, "{host}") {
+		parts = append(parts, host)
+	}
+	if owner != "" && !strings.Contains(layout, "{source}") && !strings.Contains(layout, "{owner}") {
+		parts = append(parts, owner)
+	}
+	return strings.Join(append(parts, name), "/")
+}
+
+// RepoBaseName 获取存储库键对应的存储库名
+//
+//   - 存储库键的格式为 '<存储库名>'、'<所有者>/<存储库名>' 或 '<主机地址>:<所有者>/<存储库名>'，后两种用于区分来自不同用户或主机的同名存储库
+//   - 存储库实际所在的存储库源仍由 [repo.<存储库键>] 的 source 决定
+//
+// 参数：
+//   - repoKey: 存储库键
+//
+// 返回：
+//   - 存储库名
+func RepoBaseName(repoKey string) string {
+	_, _, name := splitRepoKey(repoKey)
+	return name
+}
+
+// splitRepoKey 将存储库键拆分为主机地址、所有者和存储库名
+//
+// 参数：
+//   - repoKey: 存储库键
+//
+// 返回：
+//   - 主机地址，键中没有时为空字符串
+//   - 所有者，键中没有时为空字符串
+//   - 存储库名
+func splitRepoKey(repoKey string) (string, string, string) {
+	host, rest, found := strings.Cut(repoKey, ":")
+	if !found {
+		host, rest = "", repoKey
+	}
+	slashIndex := strings.LastIndex(rest, "/")
+	if slashIndex < 0 {
+		return host, "", rest
+	}
+	return host, rest[:slashIndex], rest[slashIndex+1:]
+}
+
+// FindRepoKey 查找存储库在配置项中的键
+//
+//   - 存储库名、存储库源地址和用户名都相同才是同一个存储库，没有单独指定存储库源的存储库同时属于 github 和 gitea
+//   - 没有找到时依次尝试 '<存储库名>'、'<用户名>/<存储库名>' 和 '<主机地址>:<用户名>/<存储库名>'，返回第一个未被占用的键
+//
+// 参数：
+//   - config: 配置项
+//   - url: 存储库源地址（仅包括主地址，例如：github.com）
+//   - username: 存储库源用户名
+//   - repoName: 存储库名
+//
+// 返回：
+//   - 存储库键
+//   - 是否已在配置项中
+func FindRepoKey(config *Config, url, username, repoName string) (string, bool) {
+	sources := GetSources(config)
+	for _, repoKey := range config.Git.Repos {
+		if RepoBaseName(repoKey) != repoName {
+			continue
+		}
+		sourceNames := []string{GetRepoSourceName(config, repoKey, "")}
+		if sourceNames[0] == "" {
+			sourceNames = []string{"github", "gitea"}
+		}
+		for _, sourceName := range sourceNames {
+			if source, ok := sources[sourceName]; ok && source.Url == url && source.Username == username {
+				return repoKey, true
+			}
+		}
+	}
+
+	candidates := []string{repoName, username + "/" + repoName, url + ":" + username + "/" + repoName}
+	for _, repoKey := range candidates {
+		if !slices.Contains(config.Git.Repos, repoKey) {
+			return repoKey, false
+		}
+	}
+	return candidates[len(candidates)-1], false
+}
+
+// DefaultSourceName 获取默认存储库源名称，github 优先，其次是 gitea
+//
+// 参数：
+//   - config: 配置项
+//
+// 返回：
+//   - 存储库源名称
+func DefaultSourceName(config *Config) string {
+	if config.Git.GithubUrl == "" && config.Git.GiteaUrl != "" {
+		return "gitea"
+	}
+	return "github"
+}
+
+// GetRepoSourceName 获取存储库使用的存储库源名称
+//
+// 参数：
+//   - config: 配置项
+//   - repoName: 存储库名
+//   - defaultSource: [repo.<存储库名>] 中没有指定存储库源时使用的存储库源
+//
+// 返回：
+//   - 存储库源名称
+func GetRepoSourceName(config *Config, repoName, defaultSource string) string {
+	if repoConfig, ok := config.Repo[repoName]; ok && repoConfig.Source != "" {
+		return repoConfig.Source
+	}
+	return defaultSource
+}
+
+// MergeRepo 将一个存储库合并到配置项中
+//
+//   - 与已有存储库源地址和用户名都相同时使用已有存储库源，否则以 sourceName 为名添加到 [source]
+//   - 已有来自其他存储库源或用户的同名存储库时，使用包含用户名（必要时还包含主机地址）的存储库键，不覆盖已有存储库
+//   - 存储库的 path 和 revision 写入 [repo.<存储库键>]，存储库地址的协议与存储库源不同时写入 scheme
+//
+// 参数：
+//   - config: 配置项
+//   - source: 存储库所在的存储库源，使用其中的 Url（仅包括主地址，例如：github.com）、Username 和 Scheme
+//   - repoName: 存储库名
+//   - path: 存储库相对于存储目录的路径，为空或与按存储布局展开的路径相同时不记录
+//   - revision: 存储库分支，为空时不记录
+//   - sourceName: 需要添加新存储库源时使用的名称
+//
+// 返回：
+//   - 存储库键
+//   - 是否是新增的存储库
+func MergeRepo(config *Config, source SourceConfig, repoName, path, revision, sourceName string) (string, bool) {
+	if config.Source == nil {
+		config.Source = make(map[string]SourceConfig)
+	}
+	if config.Repo == nil {
+		config.Repo = make(map[string]RepoConfig)
+	}
+
+	// 确定存储库键和存储库源，存储库键需在添加新存储库源之前确定
+	repoKey, exists := FindRepoKey(config, source.Url, source.Username, repoName)
+	if existingName := FindSourceName(config, source.Url, source.Username); existingName != "" {
+		sourceName = existingName
+	} else {
+		config.Source[sourceName] = SourceConfig{Url: source.Url, Username: source.Username, Scheme: source.Scheme}
+	}
+	sourceScheme := GetSources(config)[sourceName].Scheme
+
+	// 添加存储库
+	if !exists {
+		config.Git.Repos = append(config.Git.Repos, repoKey)
+	}
+
+	// 存储库的单独配置，存储库源确定后才能按存储布局判断是否需要记录 path
+	repoConfig := config.Repo[repoKey]
+	if sourceName != "github" && sourceName != "gitea" {
+		repoConfig.Source = sourceName
+	}
+	if revision != "" {
+		repoConfig.Revision = revision
+	}
+	// 存储库地址的协议与存储库源不同时单独记录，SSH 协议记为 'ssh'
+	repoConfig.Scheme = ""
+	if source.Scheme != sourceScheme {
+		repoConfig.Scheme = source.Scheme
+		if repoConfig.Scheme == "" {
+			repoConfig.Scheme = "ssh"
+		}
+	}
+	config.Repo[repoKey] = repoConfig
+	if path != "" && filepath.ToSlash(filepath.Clean(path)) != filepath.ToSlash(ExpandLayout(config, config.Storage.Layout, repoKey)) {
+		repoConfig.Path = path
+		config.Repo[repoKey] = repoConfig
+	}
+	if repoConfig == (RepoConfig{}) {
+		delete(config.Repo, repoKey)
+	}
+
+	return repoKey, !exists
+}
+
+// GetRepoScheme 获取导出存储库地址时使用的协议
+//
+//   - [repo.<存储库名>] 中的 scheme 优先，其次是存储库源的 scheme
+//
+// 参数：
+//   - config: 配置项
+//   - repoName: 存储库名
+//   - source: 存储库所在的存储库源
+//
+// 返回：
+//   - 协议，为空时表示 SSH 协议
+func GetRepoScheme(config *Config, repoName string, source SourceConfig) string {
+	if repoConfig, ok := config.Repo[repoName]; ok && repoConfig.Scheme != "" {
+		return repoConfig.Scheme
+	}
+	return source.Scheme
+}
+
+// FindSourceName 查找地址和用户名都相同的已有存储库源
+//
+// 参数：
+//   - config: 配置项
+//   - url: 存储库源地址
+//   - username: 存储库源用户名
+//
+// 返回：
+//   - 存储库源名称，不存在时为空字符串
+func FindSourceName(config *Config, url, username string) string {
+	sources := GetSources(config)
+	// github 和 gitea 优先，保证结果稳定
+	for _, name := range []string{"github", "gitea"} {
+		if source, ok := sources[name]; ok && source.Url == url && source.Username == username {
+			return name
+		}
+	}
+	for name, source := range sources {
+		if source.Url == url && source.Username == username {
+			return name
+		}
+	}
+	return ""
+}
+
 // WriteTomlConfig 写入 toml 配置文件
 //
 // 参数：
@@ -106,17 +538,37 @@ func WriteTomlConfig(filePath string) (int64, error) {
 	// 存储库初次克隆到本地后自动执行的脚本队列
 	var scriptRunQueue = []string{"create-git-hook.sh"}
 
+	// 检测已有的私钥文件，都不存在时使用 ed25519 私钥的默认路径
+	identityFiles := DetectIdentityFiles()
+	if len(identityFiles) == 0 {
+		identityFiles = []string{filepath.Join(UserInfo.HomeDir, ".ssh", defaultIdentityNames[0])}
+	}
+
 	// 定义一个 map[string]any 类型的变量并赋值
 	exampleConf := map[string]any{
+		"cache": map[string]any{
+			"path": "", // 为空时不使用本地缓存
+		},
+		"proxy": map[string]any{
+			"url": "", // 为空时使用环境变量 HTTPS_PROXY/ALL_PROXY，支持 http、https 和 socks5 代理
+		},
 		"ssh": map[string]any{
-			"rsa_file": filepath.Join(UserInfo.HomeDir, ".ssh", "id_rsa"),
+			"identity_files": identityFiles,  // 按顺序尝试的私钥文件，支持 ed25519、ecdsa、rsa 及同名的 OpenSSH 证书
+			"host_key_mode":  HostKeyAskMode, // 未知主机公钥的处理方式：ask（询问并记录）或 strict（拒绝）
 		},
 		"storage": map[string]any{
-			"path": filepath.Join(UserInfo.HomeDir, "Documents", "Repos"),
+			"path":   filepath.Join(UserInfo.HomeDir, "Documents", "Repos"),
+			"mode":   "worktree",
+			"layout": "{name}", // 可用占位符：{source}、{host}、{owner}、{group}、{name}
 		},
 		"script": map[string]any{
 			"run_queue": scriptRunQueue,
 		},
+		"verify": map[string]any{
+			"mode":            VerifyOffMode, // 拉取后校验新提交的签名：off（不校验）、report（只报告）或 reject（回退拉取）
+			"keyring":         "",            // ASCII armor 格式的 OpenPGP 公钥环文件
+			"allowed_signers": "",            // ssh-keygen 格式的 allowed_signers 文件
+		},
 		"git": map[string]any{
 			"github_url":      "github.com",
 			"github_username": "YHYJ",
@@ -0,0 +1,65 @@
+/*
+File: define_toml_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-20 10:31:05
+
+Description: 测试存储布局模板的展开和存储库路径
+*/
+
+package general
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestExpandLayoutRepoKey 包含所有者或主机地址的存储库键按存储布局展开时不与同名存储库冲突
+func TestExpandLayoutRepoKey(t *testing.T) {
+	config := &Config{Storage: StorageConfig{Path: t.TempDir()}}
+	config.Git.Repos = []string{"common", "other/common", "gitlab.com:team/common"}
+	config.Source = map[string]SourceConfig{
+		"github":           {Url: "github.com", Username: "team"},
+		"github.com-other": {Url: "github.com", Username: "other"},
+		"gitlab.com-team":  {Url: "gitlab.com", Username: "team"},
+	}
+	config.Repo = map[string]RepoConfig{
+		"other/common":           {Source: "github.com-other"},
+		"gitlab.com:team/common": {Source: "gitlab.com-team"},
+	}
+
+	tests := []struct {
+		layout string
+		want   []string
+	}{
+		{"{name}", []string{"common", "other/common", "gitlab.com/team/common"}},
+		{"{owner}/{name}", []string{"team/common", "other/common", "team/gitlab.com/common"}},
+		{"{host}/{owner}/{name}", []string{"github.com/team/common", "github.com/other/common", "gitlab.com/team/common"}},
+		{"{source}/{name}", []string{"github/common", "github.com-other/common", "gitlab.com-team/common"}},
+	}
+	for _, test := range tests {
+		for index, repoKey := range config.Git.Repos {
+			if got := filepath.ToSlash(ExpandLayout(config, test.layout, repoKey)); got != test.want[index] {
+				t.Errorf("ExpandLayout(%q, %q) = %s, want %s", test.layout, repoKey, got, test.want[index])
+			}
+		}
+	}
+}
+
+// TestGetRepoPathPinned 单独配置了 path 的存储库不受存储布局影响，相对路径基于存储目录
+func TestGetRepoPathPinned(t *testing.T) {
+	config := &Config{Storage: StorageConfig{Path: t.TempDir(), Layout: "{owner}/{name}"}}
+	config.Git.GithubUrl, config.Git.GithubUsername = "github.com", "YHYJ"
+	config.Repo = map[string]RepoConfig{"YHYJ": {Path: "YHYJ"}, "tools": {Path: "/srv/tools"}}
+
+	tests := map[string]string{
+		"YHYJ":    filepath.Join(config.Storage.Path, "YHYJ"),
+		"tools":   "/srv/tools",
+		"curator": filepath.Join(config.Storage.Path, "YHYJ", "curator"),
+	}
+	for repoName, want := range tests {
+		if got := GetRepoPath(config, repoName); got != want {
+			t.Errorf("GetRepoPath(%q) = %s, want %s", repoName, got, want)
+		}
+	}
+}
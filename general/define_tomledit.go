@@ -0,0 +1,624 @@
+/*
+File: define_tomledit.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 18:04:52
+
+Description: 在保留原有格式和注释的前提下修改 toml 配置文件
+
+- 重新生成整个文件会丢失注释和排版，这里以文本方式做最小修改
+*/
+
+package general
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	tomlTableRegex      = regexp.MustCompile(`^\s*\[\s*([^\[\]]+?)\s*\]\s*(#.*)?$`) // 表头，不匹配数组表
+	tomlArrayTableRegex = regexp.MustCompile(`^\s*\[\[`)                            // 数组表表头
+	tomlReposRegex      = regexp.MustCompile(`^\s*repos\s*=\s*\[`)                  // [git] 表中的 repos 键
+	tomlBareKey         = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)                    // 不需要加引号的键
+)
+
+// SaveConfigChanges 将配置项的修改以文本方式写入配置文件，保留原有格式和注释
+//
+//   - 与配置文件的当前内容比较，只处理 git.repos 的增删以及 [source.<名称>] 和 [repo.<存储库名>] 的增删改
+//   - 存储库源的访问令牌（token）不会写入配置文件，应使用 'auth login' 保存到凭据文件
+//
+// 参数：
+//   - filePath: 配置文件路径
+//   - config: 修改后的配置项
+//
+// 返回：
+//   - 错误信息
+func SaveConfigChanges(filePath string, config *Config) error {
+	configTree, err := GetTomlConfig(filePath)
+	if err != nil {
+		return err
+	}
+	current, err := LoadConfigToStruct(configTree)
+	if err != nil {
+		return err
+	}
+
+	// git.repos
+	for _, repoName := range current.Git.Repos {
+		if !slices.Contains(config.Git.Repos, repoName) {
+			if err := RemoveRepoFromConfigFile(filePath, repoName); err != nil {
+				return err
+			}
+		}
+	}
+	for _, repoName := range config.Git.Repos {
+		if !slices.Contains(current.Git.Repos, repoName) {
+			if err := AppendRepoToConfigFile(filePath, repoName); err != nil {
+				return err
+			}
+		}
+	}
+
+	// [source.<名称>]
+	for _, name := range sortedKeys(current.Source) {
+		if _, ok := config.Source[name]; !ok {
+			if err := RemoveTableFromConfigFile(filePath, []string{"source", name}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, name := range sortedKeys(config.Source) {
+		currentSource, exists := current.Source[name]
+		if err := saveTableChanges(filePath, []string{"source", name}, sourceValues(currentSource), sourceValues(config.Source[name]), exists); err != nil {
+			return err
+		}
+	}
+
+	// [repo.<存储库名>]
+	for _, name := range sortedKeys(current.Repo) {
+		if _, ok := config.Repo[name]; !ok {
+			if err := RemoveTableFromConfigFile(filePath, []string{"repo", name}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, name := range sortedKeys(config.Repo) {
+		currentRepo, exists := current.Repo[name]
+		if err := saveTableChanges(filePath, []string{"repo", name}, repoValues(currentRepo), repoValues(config.Repo[name]), exists); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveTableChanges 将一个表中字符串键值的修改写入配置文件
+//
+// 参数：
+//   - filePath: 配置文件路径
+//   - keys: 表名的各级键
+//   - currentValues: 配置文件中当前的键值
+//   - values: 修改后的键值，值为空字符串表示删除该键
+//   - exists: 配置文件中是否已存在该表
+//
+// 返回：
+//   - 错误信息
+func saveTableChanges(filePath string, keys []string, currentValues, values map[string]string, exists bool) error {
+	if !exists {
+		newValues := make(map[string]string)
+		for key, value := range values {
+			if value != "" {
+				newValues[key] = value
+			}
+		}
+		if len(newValues) == 0 {
+			return nil
+		}
+		return AppendTableToConfigFile(filePath, keys, newValues)
+	}
+	for _, key := range sortedKeys(values) {
+		if values[key] == currentValues[key] {
+			continue
+		}
+		var err error
+		if values[key] == "" {
+			err = RemoveTableValueFromConfigFile(filePath, keys, key)
+		} else {
+			err = SetTableValueInConfigFile(filePath, keys, key, values[key])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sourceValues 获取存储库源配置中需要写入配置文件的字符串键值，不包括访问令牌
+//
+// 参数：
+//   - source: 存储库源配置
+//
+// 返回：
+//   - 键值
+func sourceValues(source SourceConfig) map[string]string {
+	return map[string]string{
+		"url":      source.Url,
+		"username": source.Username,
+		"type":     source.Type,
+		"api":      source.Api,
+		"proxy":    source.Proxy,
+		"scheme":   source.Scheme,
+	}
+}
+
+// repoValues 获取存储库配置中的字符串键值
+//
+// 参数：
+//   - repo: 存储库配置
+//
+// 返回：
+//   - 键值
+func repoValues(repo RepoConfig) map[string]string {
+	return map[string]string{
+		"source":   repo.Source,
+		"path":     repo.Path,
+		"revision": repo.Revision,
+		"group":    repo.Group,
+		"scheme":   repo.Scheme,
+	}
+}
+
+// AppendRepoToConfigFile 将存储库名追加到配置文件 [git] 表的 repos 数组末尾
+//
+//   - 单行数组追加在最后一个元素之后，多行数组新起一行并沿用上一个元素的缩进
+//
+// 参数：
+//   - filePath: 配置文件路径
+//   - repoName: 存储库名
+//
+// 返回：
+//   - 错误信息
+func AppendRepoToConfigFile(filePath, repoName string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(data), "\n")
+
+	// 查找 [git] 表中的 repos 键
+	startLine := -1
+	tableLine, endLine := findTomlTable(lines, []string{"git"})
+	for index := tableLine + 1; tableLine >= 0 && index < endLine; index++ {
+		if tomlReposRegex.MatchString(lines[index]) {
+			startLine = index
+			break
+		}
+	}
+	if startLine < 0 {
+		return fmt.Errorf("Key 'repos' not found in [git] of %s", filePath)
+	}
+
+	// 找到数组的结束位置和最后一个元素的位置
+	endLine, endColumn, lastLine, lastColumn, err := scanTomlArray(lines, startLine, strings.Index(lines[startLine], "["))
+	if err != nil {
+		return fmt.Errorf("Key 'repos' in %s: %s", filePath, err)
+	}
+
+	value := strconv.Quote(repoName)
+	switch {
+	case lastLine < 0: // 空数组
+		lines[endLine] = lines[endLine][:endColumn] + value + lines[endLine][endColumn:]
+	case lastLine == endLine || strings.TrimSpace(lines[endLine][:endColumn]) != "": // 最后一个元素和 ']' 在同一行
+		insertAt := lastColumn + 1
+		separator := ", "
+		if lines[lastLine][lastColumn] == ',' {
+			separator = " "
+		}
+		lines[lastLine] = lines[lastLine][:insertAt] + separator + value + lines[lastLine][insertAt:]
+	default: // 多行数组
+		lastContent := lines[lastLine]
+		indent := lastContent[:len(lastContent)-len(strings.TrimLeft(lastContent, " \t"))]
+		newLine := indent + value
+		if lastContent[lastColumn] == ',' {
+			newLine += ","
+		} else {
+			lines[lastLine] = lastContent[:lastColumn+1] + "," + lastContent[lastColumn+1:]
+		}
+		lines = append(lines[:lastLine+1], append([]string{newLine}, lines[lastLine+1:]...)...)
+	}
+
+	return os.WriteFile(filePath, []byte(strings.Join(lines, "\n")), 0644)
+}
+
+// RemoveRepoFromConfigFile 从配置文件 [git] 表的 repos 数组中删除存储库名
+//
+//   - 元素独占一行时删除整行，否则只删除该元素及其后的逗号
+//
+// 参数：
+//   - filePath: 配置文件路径
+//   - repoName: 存储库名
+//
+// 返回：
+//   - 错误信息
+func RemoveRepoFromConfigFile(filePath, repoName string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(data), "\n")
+
+	startLine := -1
+	tableLine, tableEndLine := findTomlTable(lines, []string{"git"})
+	for index := tableLine + 1; tableLine >= 0 && index < tableEndLine; index++ {
+		if tomlReposRegex.MatchString(lines[index]) {
+			startLine = index
+			break
+		}
+	}
+	if startLine < 0 {
+		return fmt.Errorf("Key 'repos' not found in [git] of %s", filePath)
+	}
+	endLine, _, _, _, err := scanTomlArray(lines, startLine, strings.Index(lines[startLine], "["))
+	if err != nil {
+		return fmt.Errorf("Key 'repos' in %s: %s", filePath, err)
+	}
+
+	// 匹配带引号的元素及其后的逗号
+	elementRegex := regexp.MustCompile(`(?:"` + regexp.QuoteMeta(strings.Trim(strconv.Quote(repoName), `"`)) + `"|'` + regexp.QuoteMeta(repoName) + `')\s*,?\s*`)
+	for index := startLine; index <= endLine; index++ {
+		line := lines[index]
+		location := elementRegex.FindStringIndex(line)
+		if location == nil {
+			continue
+		}
+		if rest := strings.TrimSpace(line[:location[0]] + line[location[1]:]); rest == "" || strings.HasPrefix(rest, "#") {
+			lines = append(lines[:index], lines[index+1:]...)
+		} else {
+			newLine := line[:location[0]] + line[location[1]:]
+			// 删除最后一个元素后，去掉残留的 ', ]' 中的逗号
+			newLine = regexp.MustCompile(`,\s*\]`).ReplaceAllString(newLine, "]")
+			lines[index] = newLine
+		}
+		return os.WriteFile(filePath, []byte(strings.Join(lines, "\n")), 0644)
+	}
+	return fmt.Errorf("Repository %s not found in 'repos' of %s", repoName, filePath)
+}
+
+// AppendTableToConfigFile 在配置文件末尾追加一个表，表已存在时更新表中的键值，不重复添加
+//
+// 参数：
+//   - filePath: 配置文件路径
+//   - keys: 表名的各级键，例如 ["repo", "curator"] 表示 [repo.curator]
+//   - values: 表中的字符串键值对
+//
+// 返回：
+//   - 错误信息
+func AppendTableToConfigFile(filePath string, keys []string, values map[string]string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	var valueKeys []string
+	for key := range values {
+		valueKeys = append(valueKeys, key)
+	}
+	sort.Strings(valueKeys)
+
+	// 表已存在时逐个设置键值
+	if tableLine, _ := findTomlTable(strings.Split(string(data), "\n"), keys); tableLine >= 0 {
+		for _, key := range valueKeys {
+			if err := SetTableValueInConfigFile(filePath, keys, key, values[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(strings.TrimRight(string(data), "\n"))
+	builder.WriteString("\n\n[" + tomlTableName(keys) + "]\n")
+	for _, key := range valueKeys {
+		builder.WriteString("  " + tomlKey(key) + " = " + strconv.Quote(values[key]) + "\n")
+	}
+
+	return os.WriteFile(filePath, []byte(builder.String()), 0644)
+}
+
+// SetTableValueInConfigFile 设置配置文件中某个表的字符串键值
+//
+//   - 键已存在时替换该行，不存在时添加到表的最后一个键之后，表不存在时在文件末尾追加
+//
+// 参数：
+//   - filePath: 配置文件路径
+//   - keys: 表名的各级键，例如 ["storage"] 表示 [storage]
+//   - key: 键
+//   - value: 值
+//
+// 返回：
+//   - 错误信息
+func SetTableValueInConfigFile(filePath string, keys []string, key, value string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(data), "\n")
+	keyRegex := tomlKeyRegex(key)
+
+	tableLine, endLine := findTomlTable(lines, keys)
+	if tableLine < 0 {
+		return AppendTableToConfigFile(filePath, keys, map[string]string{key: value})
+	}
+	lastKeyLine := tableLine
+	for index := tableLine + 1; index < endLine; index++ {
+		line := lines[index]
+		if match := keyRegex.FindStringSubmatch(line); match != nil {
+			lines[index] = match[1] + tomlKey(key) + " = " + strconv.Quote(value)
+			return os.WriteFile(filePath, []byte(strings.Join(lines, "\n")), 0644)
+		}
+		if trimmedLine := strings.TrimSpace(line); trimmedLine != "" && !strings.HasPrefix(trimmedLine, "#") {
+			lastKeyLine = index
+		}
+	}
+
+	// 沿用表中最后一个键的缩进
+	indent := ""
+	if lastKeyLine > tableLine {
+		lastContent := lines[lastKeyLine]
+		indent = lastContent[:len(lastContent)-len(strings.TrimLeft(lastContent, " \t"))]
+	}
+	newLine := indent + tomlKey(key) + " = " + strconv.Quote(value)
+	lines = append(lines[:lastKeyLine+1], append([]string{newLine}, lines[lastKeyLine+1:]...)...)
+
+	return os.WriteFile(filePath, []byte(strings.Join(lines, "\n")), 0644)
+}
+
+// RemoveTableValueFromConfigFile 删除配置文件中某个表的一个单行键值，表或键不存在时不做修改
+//
+// 参数：
+//   - filePath: 配置文件路径
+//   - keys: 表名的各级键
+//   - key: 键
+//
+// 返回：
+//   - 错误信息
+func RemoveTableValueFromConfigFile(filePath string, keys []string, key string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(data), "\n")
+	keyRegex := tomlKeyRegex(key)
+
+	tableLine, endLine := findTomlTable(lines, keys)
+	for index := tableLine + 1; tableLine >= 0 && index < endLine; index++ {
+		if keyRegex.MatchString(lines[index]) {
+			lines = append(lines[:index], lines[index+1:]...)
+			return os.WriteFile(filePath, []byte(strings.Join(lines, "\n")), 0644)
+		}
+	}
+	return nil
+}
+
+// RemoveTableFromConfigFile 删除配置文件中的一个表（表头及其所有键值），表不存在时不做修改
+//
+//   - 表前紧邻的注释和空行一并删除
+//
+// 参数：
+//   - filePath: 配置文件路径
+//   - keys: 表名的各级键
+//
+// 返回：
+//   - 错误信息
+func RemoveTableFromConfigFile(filePath string, keys []string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(data), "\n")
+
+	tableLine, endLine := findTomlTable(lines, keys)
+	if tableLine < 0 {
+		return nil
+	}
+	// 下一个表前的注释和空行属于下一个表，保留
+	for endLine > tableLine+1 && endLine < len(lines) {
+		if trimmedLine := strings.TrimSpace(lines[endLine-1]); trimmedLine != "" && !strings.HasPrefix(trimmedLine, "#") {
+			break
+		}
+		endLine--
+	}
+	startLine := tableLine
+	for startLine > 0 {
+		trimmedLine := strings.TrimSpace(lines[startLine-1])
+		if trimmedLine != "" && !strings.HasPrefix(trimmedLine, "#") {
+			break
+		}
+		startLine--
+	}
+	lines = append(lines[:startLine], lines[endLine:]...)
+
+	return os.WriteFile(filePath, []byte(strings.Join(lines, "\n")), 0644)
+}
+
+// findTomlTable 查找表的位置
+//
+// 参数：
+//   - lines: 文件的所有行
+//   - keys: 表名的各级键
+//
+// 返回：
+//   - 表头所在行，不存在时为 -1
+//   - 表结束的位置（下一个表头所在行或文件行数）
+func findTomlTable(lines []string, keys []string) (int, int) {
+	tableLine := -1
+	for index, line := range lines {
+		match := tomlTableRegex.FindStringSubmatch(line)
+		if match == nil && !tomlArrayTableRegex.MatchString(line) {
+			continue
+		}
+		if tableLine >= 0 {
+			return tableLine, index
+		}
+		if match != nil && slices.Equal(parseTomlTableName(match[1]), keys) {
+			tableLine = index
+		}
+	}
+	return tableLine, len(lines)
+}
+
+// parseTomlTableName 将 toml 格式的表名拆分为各级键，去除引号和空白
+//
+// 参数：
+//   - name: toml 格式的表名，例如 'repo."my.repo"'
+//
+// 返回：
+//   - 各级键
+func parseTomlTableName(name string) []string {
+	var keys []string
+	builder := strings.Builder{}
+	quote := byte(0)
+	for index := 0; index < len(name); index++ {
+		char := name[index]
+		switch {
+		case quote != 0 && char == quote:
+			quote = 0
+		case quote == '"' && char == '\\' && index+1 < len(name):
+			index++
+			builder.WriteByte(char)
+			builder.WriteByte(name[index])
+			continue
+		case quote != 0:
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '.':
+			keys = append(keys, unquoteTomlKey(strings.TrimSpace(builder.String())))
+			builder.Reset()
+			continue
+		}
+		builder.WriteByte(char)
+	}
+	return append(keys, unquoteTomlKey(strings.TrimSpace(builder.String())))
+}
+
+// unquoteTomlKey 去除键的引号
+//
+// 参数：
+//   - key: toml 格式的键
+//
+// 返回：
+//   - 键
+func unquoteTomlKey(key string) string {
+	if len(key) >= 2 && key[0] == '"' && key[len(key)-1] == '"' {
+		if unquotedKey, err := strconv.Unquote(key); err == nil {
+			return unquotedKey
+		}
+	}
+	if len(key) >= 2 && key[0] == '\'' && key[len(key)-1] == '\'' {
+		return key[1 : len(key)-1]
+	}
+	return key
+}
+
+// tomlKeyRegex 构建匹配单行键值的正则表达式，第一个分组为缩进
+//
+// 参数：
+//   - key: 键
+//
+// 返回：
+//   - 正则表达式
+func tomlKeyRegex(key string) *regexp.Regexp {
+	return regexp.MustCompile(`^(\s*)(?:` + regexp.QuoteMeta(key) + `|` + regexp.QuoteMeta(strconv.Quote(key)) + `)\s*=`)
+}
+
+// scanTomlArray 从 '[' 开始扫描 toml 数组，跳过字符串和注释
+//
+// 参数：
+//   - lines: 文件的所有行
+//   - startLine: '[' 所在行
+//   - startColumn: '[' 所在列
+//
+// 返回：
+//   - ']' 所在行和列
+//   - 最后一个有效字符（元素或逗号）所在行和列，空数组时为 -1
+//   - 错误信息
+func scanTomlArray(lines []string, startLine, startColumn int) (int, int, int, int, error) {
+	depth := 0
+	lastLine, lastColumn := -1, -1
+	for lineNo := startLine; lineNo < len(lines); lineNo++ {
+		line := lines[lineNo]
+		column := 0
+		if lineNo == startLine {
+			column = startColumn
+		}
+		for ; column < len(line); column++ {
+			switch char := line[column]; char {
+			case '#': // 注释到行尾
+				column = len(line)
+			case '"', '\'': // 跳过字符串
+				endColumn := column + 1
+				for endColumn < len(line) && line[endColumn] != char {
+					if char == '"' && line[endColumn] == '\\' {
+						endColumn++
+					}
+					endColumn++
+				}
+				if endColumn >= len(line) {
+					return 0, 0, 0, 0, fmt.Errorf("unterminated string at line %d", lineNo+1)
+				}
+				column = endColumn
+				lastLine, lastColumn = lineNo, column
+			case '[':
+				depth++
+				if depth > 1 {
+					lastLine, lastColumn = lineNo, column
+				}
+			case ']':
+				depth--
+				if depth == 0 {
+					return lineNo, column, lastLine, lastColumn, nil
+				}
+				lastLine, lastColumn = lineNo, column
+			case ' ', '\t', '\r':
+			default:
+				lastLine, lastColumn = lineNo, column
+			}
+		}
+	}
+	return 0, 0, 0, 0, fmt.Errorf("unterminated array at line %d", startLine+1)
+}
+
+// tomlKey 将键转换为 toml 格式，包含特殊字符时加引号
+//
+// 参数：
+//   - key: 键
+//
+// 返回：
+//   - toml 格式的键
+func tomlKey(key string) string {
+	if tomlBareKey.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+// tomlTableName 将表名的各级键转换为 toml 格式的表名
+//
+// 参数：
+//   - keys: 表名的各级键
+//
+// 返回：
+//   - toml 格式的表名，例如 'repo."my.repo"'
+func tomlTableName(keys []string) string {
+	var quotedKeys []string
+	for _, key := range keys {
+		quotedKeys = append(quotedKeys, tomlKey(key))
+	}
+	return strings.Join(quotedKeys, ".")
+}
@@ -0,0 +1,110 @@
+/*
+File: define_tomledit_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 17:12:05
+
+Description: 测试以文本方式修改 toml 配置文件
+*/
+
+package general
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSaveConfigChangesWithoutToken 保存配置项时保留注释且不写入访问令牌
+func TestSaveConfigChangesWithoutToken(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "config.toml")
+	original := "# curator\n[git]\nrepos = [\n  \"curator\", # self\n]\n"
+	if err := os.WriteFile(filePath, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	configTree, err := GetTomlConfig(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfigToStruct(configTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Git.Repos = append(config.Git.Repos, "tools")
+	config.Source = map[string]SourceConfig{"work": {Url: "git.example.com", Username: "ops", Type: "gitea", Token: "plaintext-secret"}}
+	config.Repo = map[string]RepoConfig{"tools": {Source: "work"}}
+	if err := SaveConfigChanges(filePath, config); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if strings.Contains(content, "plaintext-secret") || strings.Contains(content, "token") {
+		t.Errorf("token written to config file:\n%s", content)
+	}
+	for _, want := range []string{"# curator", "\"curator\", # self", "\"tools\",", "[source.work]", "type = \"gitea\"", "[repo.tools]"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("config file does not contain %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "[cache]") {
+		t.Errorf("unrelated table added to config file:\n%s", content)
+	}
+
+	// 已有的令牌保持原样，不会被删除或改写
+	if err := os.WriteFile(filePath, []byte(original+"\n[source.work]\nurl = \"git.example.com\"\nusername = \"ops\"\ntoken = \"kept\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config.Source["work"] = SourceConfig{Url: "git.example.com", Username: "ops", Token: "changed"}
+	if err := SaveConfigChanges(filePath, config); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = os.ReadFile(filePath); err != nil {
+		t.Fatal(err)
+	}
+	if content := string(data); !strings.Contains(content, "token = \"kept\"") || strings.Contains(content, "changed") {
+		t.Errorf("existing token modified:\n%s", content)
+	}
+}
+
+// TestAppendTableToConfigFileExisting 表已存在时更新键值，不重复添加
+func TestAppendTableToConfigFileExisting(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "config.toml")
+	original := "[git]\nrepos = []\n\n# work source\n[source.\"work\"]\n  url = \"old.example.com\"\n\n[storage]\npath = \"/tmp\"\n"
+	if err := os.WriteFile(filePath, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AppendTableToConfigFile(filePath, []string{"source", "work"}, map[string]string{"url": "git.example.com", "username": "ops"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if count := strings.Count(content, "work\"]"); count != 1 {
+		t.Errorf("table [source.work] appears %d times:\n%s", count, content)
+	}
+	want := "[source.\"work\"]\n  url = \"git.example.com\"\n  username = \"ops\"\n"
+	if !strings.Contains(content, want) || !strings.Contains(content, "# work source") {
+		t.Errorf("config file does not contain the updated table %q:\n%s", want, content)
+	}
+
+	configTree, err := GetTomlConfig(filePath)
+	if err != nil {
+		t.Fatalf("invalid toml after update: %s\n%s", err, content)
+	}
+	config, err := LoadConfigToStruct(configTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source := config.Source["work"]; source.Url != "git.example.com" || source.Username != "ops" {
+		t.Errorf("source work = %+v, want the updated values", source)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+File: define_url.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 11:08:45
+
+Description: 解析存储库地址
+*/
+
+package general
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const defaultSSHUser = "git" // 构建 SSH 协议的存储库地址时使用的用户名
+
+// ParseRepoUrl 解析存储库地址，得到主机地址和路径
+//
+//   - 支持 'git@host:owner/name.git'、'ssh://git@host:port/owner/name.git' 和 'https://host/owner/name.git' 格式
+//   - 路径去除了首尾的 '/' 和 '.git' 后缀
+//   - 存储库源只记录主机地址，地址中带有非默认端口时返回错误，参见 checkRepoUrlPort
+//
+// 参数：
+//   - rawUrl: 存储库地址
+//
+// 返回：
+//   - 主机地址（不包括用户名和端口）
+//   - 路径
+//   - 错误信息
+func ParseRepoUrl(rawUrl string) (string, string, error) {
+	rawUrl = strings.TrimSpace(rawUrl)
+
+	var host, path string
+	if strings.Contains(rawUrl, "://") { // 'scheme://[user@]host[:port]/path' 格式
+		parsedUrl, err := url.Parse(rawUrl)
+		if err != nil {
+			return "", "", err
+		}
+		if err := checkRepoUrlPort(parsedUrl); err != nil {
+			return "", "", err
+		}
+		host, path = parsedUrl.Hostname(), parsedUrl.Path
+	} else if colonIndex := strings.Index(rawUrl, ":"); colonIndex > 0 && !strings.Contains(rawUrl[:colonIndex], "/") { // '[user@]host:path' 格式
+		host, path = rawUrl[:colonIndex], rawUrl[colonIndex+1:]
+		if atIndex := strings.LastIndex(host, "@"); atIndex >= 0 {
+			host = host[atIndex+1:]
+		}
+	} else {
+		return "", "", fmt.Errorf("Unsupported repository url: %s", rawUrl)
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" {
+		return "", "", fmt.Errorf("Unsupported repository url: %s", rawUrl)
+	}
+
+	return host, path, nil
+}
+
+// checkRepoUrlPort 检查存储库地址中的端口
+//
+//   - 由存储库源构建的地址总是使用协议的默认端口，因此不能保留其他端口
+//   - SSH 地址的端口与 ~/.ssh/config 中该主机的 Port 相同时可以使用，连接时由 ssh_config 应用
+//
+// 参数：
+//   - parsedUrl: 解析后的存储库地址
+//
+// 返回：
+//   - 错误信息
+func checkRepoUrlPort(parsedUrl *url.URL) error {
+	port := parsedUrl.Port()
+	scheme := strings.ToLower(parsedUrl.Scheme)
+	defaultPorts := map[string]string{"ssh": "22", "git+ssh": "22", "https": "443", "http": "80"}
+	if port == "" || port == defaultPorts[scheme] {
+		return nil
+	}
+	if scheme == "ssh" || scheme == "git+ssh" {
+		if strconv.Itoa(ResolveSSHHost(parsedUrl.Hostname()).Port) == port {
+			return nil
+		}
+		return fmt.Errorf("Repository url %s uses port %s, add 'Port %s' under 'Host %s' in ~/.ssh/config and try again", parsedUrl.Redacted(), port, port, parsedUrl.Hostname())
+	}
+	return fmt.Errorf("Repository url %s uses port %s, only the default port of %s is supported", parsedUrl.Redacted(), port, scheme)
+}
+
+// RepoUrlScheme 获取存储库地址使用的协议
+//
+// 参数：
+//   - rawUrl: 存储库地址
+//
+// 返回：
+//   - 'https' 或 'http'，SSH 协议（包括 'git@host:path' 格式）为空字符串
+func RepoUrlScheme(rawUrl string) string {
+	rawUrl = strings.TrimSpace(rawUrl)
+	for _, scheme := range []string{"https", "http"} {
+		if strings.HasPrefix(strings.ToLower(rawUrl), scheme+"://") {
+			return scheme
+		}
+	}
+	return ""
+}
+
+// BuildSchemeRepoUrl 按协议构建存储库地址
+//
+//   - scheme 为 https 或 http 时格式为 '<scheme>://<地址>/<用户名>/<存储库名>.git'，否则与 BuildRepoUrl 相同
+//
+// 参数：
+//   - scheme: 协议
+//   - URL: 远端存储库地址（仅包括主地址，例如：github.com）
+//   - username: 远端存储库用户名
+//   - repoName: 远端存储库名称
+//
+// 返回：
+//   - 存储库地址
+func BuildSchemeRepoUrl(scheme, URL, username, repoName string) string {
+	if scheme == "https" || scheme == "http" {
+		return scheme + "://" + URL + "/" + username + "/" + repoName + ".git"
+	}
+	return BuildRepoUrl(URL, username, repoName)
+}
+
+// BuildRepoUrl 构建 SSH 协议的存储库地址，格式为 'git@<地址>:<用户名>/<存储库名>.git'
+//
+// 参数：
+//   - URL: 远端存储库地址（仅包括主地址，例如：github.com）
+//   - username: 远端存储库用户名
+//   - repoName: 远端存储库名称
+//
+// 返回：
+//   - 存储库地址
+func BuildRepoUrl(URL, username, repoName string) string {
+	return defaultSSHUser + "@" + URL + ":" + username + "/" + repoName + ".git"
+}
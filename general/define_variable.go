@@ -42,18 +42,34 @@ var UserName = func() string {
 	return GetVariable("USER")
 }()
 
-var Platform = runtime.GOOS                   // 操作系统
-var Arch = runtime.GOARCH                     // 系统架构
-var Sep = string(filepath.Separator)          // 路径分隔符
-var UserInfo, _ = GetUserInfoByName(UserName) // 用户信息
-var Language = GetLanguage()                  // 系统语言
+var Platform = runtime.GOOS          // 操作系统
+var Arch = runtime.GOARCH            // 系统架构
+var Sep = string(filepath.Separator) // 路径分隔符
+var Language = GetLanguage()         // 系统语言
+
+// 用户信息，无法通过用户名获取（例如没有设置 USER 变量）时使用当前用户
+var UserInfo = func() *user.User {
+	if userInfo, err := GetUserInfoByName(UserName); err == nil {
+		return userInfo
+	}
+	userInfo, _ := user.Current()
+	return userInfo
+}()
 
 var (
-	programDir = strings.ToLower(Name)                      // 程序目录
-	configDir  = filepath.Join(UserInfo.HomeDir, ".config") // 配置目录
-	configFile = "config.toml"                              // 配置文件
-
-	ConfigFile = filepath.Join(configDir, programDir, configFile) // 配置文件路径
+	programDir  = strings.ToLower(Name)                      // 程序目录
+	configDir   = filepath.Join(UserInfo.HomeDir, ".config") // 配置目录
+	configFile  = "config.toml"                              // 配置文件
+	lockFile    = "curator.lock"                             // 锁文件
+	credFile    = "credentials"                              // 凭据文件
+	historyFile = "history.jsonl"                            // 拉取记录文件
+	mirrorFile  = "push_mirrors.json"                        // 推送镜像凭据指纹文件
+
+	ConfigFile     = filepath.Join(configDir, programDir, configFile)  // 配置文件路径
+	LockFile       = filepath.Join(configDir, programDir, lockFile)    // 锁文件路径
+	CredentialFile = filepath.Join(configDir, programDir, credFile)    // 凭据文件路径
+	HistoryFile    = filepath.Join(configDir, programDir, historyFile) // 拉取记录文件路径
+	PushMirrorFile = filepath.Join(configDir, programDir, mirrorFile)  // 推送镜像记录文件路径
 )
 
 // ---------- 变量相关函数
@@ -0,0 +1,176 @@
+/*
+File: define_vcstool.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 13:05:33
+
+Description: 操作 vcstool 的 .repos 文件
+
+- .repos 文件中 repositories 的键是存储库的本地路径，值包括 type、url 和 version
+*/
+
+package general
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 用于转换 .repos 文件的结构体
+type VcsRepos struct {
+	Repositories map[string]VcsRepo `yaml:"repositories"`
+}
+type VcsRepo struct {
+	Type    string `yaml:"type"`
+	Url     string `yaml:"url"`
+	Version string `yaml:"version,omitempty"`
+}
+
+// ReadVcsRepos 读取 .repos 文件
+//
+// 参数：
+//   - filePath: .repos 文件路径
+//
+// 返回：
+//   - .repos 文件内容
+//   - 错误信息
+func ReadVcsRepos(filePath string) (*VcsRepos, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var vcsRepos VcsRepos
+	if err := yaml.Unmarshal(data, &vcsRepos); err != nil {
+		return nil, err
+	}
+	return &vcsRepos, nil
+}
+
+// WriteVcsRepos 将 .repos 文件内容以 YAML 格式写入 writer
+//
+// 参数：
+//   - writer: 写入目标
+//   - vcsRepos: .repos 文件内容
+//
+// 返回：
+//   - 错误信息
+func WriteVcsRepos(writer io.Writer, vcsRepos *VcsRepos) error {
+	encoder := yaml.NewEncoder(writer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(vcsRepos); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// ImportVcsRepos 将 .repos 文件内容合并到配置项中
+//
+//   - 只支持 type 为 git 的存储库
+//   - 记录存储库地址的协议，导出时使用相同的协议
+//   - 同名存储库来自不同存储库源或用户时使用包含用户名的存储库键区分
+//
+// 参数：
+//   - config: 配置项
+//   - vcsRepos: .repos 文件内容
+//
+// 返回：
+//   - 新增的存储库键
+//   - 错误信息
+func ImportVcsRepos(config *Config, vcsRepos *VcsRepos) ([]string, error) {
+	var addedRepos []string
+	for _, repoPath := range sortedKeys(vcsRepos.Repositories) {
+		vcsRepo := vcsRepos.Repositories[repoPath]
+		if vcsRepo.Type != "" && vcsRepo.Type != "git" {
+			return addedRepos, fmt.Errorf("Repository %s: unsupported type '%s'", repoPath, vcsRepo.Type)
+		}
+		url, username, repoName, err := SplitRepoUrl(vcsRepo.Url)
+		if err != nil {
+			return addedRepos, fmt.Errorf("Repository %s: %s", repoPath, err)
+		}
+		source := SourceConfig{Url: url, Username: username, Scheme: RepoUrlScheme(vcsRepo.Url)}
+		if repoKey, added := MergeRepo(config, source, repoName, filepath.ToSlash(filepath.Clean(repoPath)), vcsRepo.Version, NewSourceName(url, username)); added {
+			addedRepos = append(addedRepos, repoKey)
+		}
+	}
+	return addedRepos, nil
+}
+
+// ExportVcsRepos 将配置项转换为 .repos 文件内容
+//
+// 参数：
+//   - config: 配置项
+//   - defaultSource: 存储库没有指定存储库源时使用的存储库源
+//
+// 返回：
+//   - .repos 文件内容
+func ExportVcsRepos(config *Config, defaultSource string) *VcsRepos {
+	vcsRepos := &VcsRepos{Repositories: make(map[string]VcsRepo)}
+	sources := GetSources(config)
+	for _, repoName := range config.Git.Repos {
+		source := sources[GetRepoSourceName(config, repoName, defaultSource)]
+		vcsRepo := VcsRepo{
+			Type: "git",
+			Url:  BuildSchemeRepoUrl(GetRepoScheme(config, repoName, source), source.Url, source.Username, RepoBaseName(repoName)),
+		}
+		if repoConfig, ok := config.Repo[repoName]; ok {
+			vcsRepo.Version = repoConfig.Revision
+		}
+		vcsRepos.Repositories[getRelativeRepoPath(config, repoName)] = vcsRepo
+	}
+	return vcsRepos
+}
+
+// SplitRepoUrl 将存储库地址拆分为主机地址、用户名和存储库名
+//
+// 参数：
+//   - rawUrl: 存储库地址
+//
+// 返回：
+//   - 主机地址
+//   - 用户名
+//   - 存储库名
+//   - 错误信息
+func SplitRepoUrl(rawUrl string) (string, string, string, error) {
+	host, path, err := ParseRepoUrl(rawUrl)
+	if err != nil {
+		return "", "", "", err
+	}
+	slashIndex := strings.LastIndex(path, "/")
+	if slashIndex <= 0 {
+		return "", "", "", fmt.Errorf("Unable to determine the owner of %s", rawUrl)
+	}
+	return host, path[:slashIndex], path[slashIndex+1:], nil
+}
+
+// NewSourceName 为新的存储库源生成名称，格式为 '<主机地址>-<用户名>'
+//
+// 参数：
+//   - url: 存储库源地址
+//   - username: 存储库源用户名
+//
+// 返回：
+//   - 存储库源名称
+func NewSourceName(url, username string) string {
+	return url + "-" + strings.ReplaceAll(username, "/", "-")
+}
+
+// getRelativeRepoPath 获取存储库相对于存储目录的路径
+//
+// 参数：
+//   - config: 配置项
+//   - repoName: 存储库名
+//
+// 返回：
+//   - 相对路径，无法计算时为存储库名
+func getRelativeRepoPath(config *Config, repoName string) string {
+	relPath, err := filepath.Rel(config.Storage.Path, GetRepoPath(config, repoName))
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return repoName
+	}
+	return filepath.ToSlash(relPath)
+}
@@ -0,0 +1,134 @@
+/*
+File: define_vcstool_test.go
+Author: YJ
+Email: yj1516268@outlook.com
+Created Time: 2026-10-19 16:40:12
+
+Description: 测试 vcstool 的 .repos 文件的导入和导出
+*/
+
+package general
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newVcsTestConfig 创建只有存储目录的配置项
+func newVcsTestConfig(t *testing.T) *Config {
+	return &Config{Storage: StorageConfig{Path: t.TempDir()}}
+}
+
+// TestVcsReposRoundTrip 导入、导出再导入后，.repos 文件和配置项都不变
+func TestVcsReposRoundTrip(t *testing.T) {
+	vcsRepos, err := ReadVcsRepos(filepath.Join("testdata", "vcstool", "https.repos"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := newVcsTestConfig(t)
+	addedRepos, err := ImportVcsRepos(config, vcsRepos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"common", "tools", "parser"}; !reflect.DeepEqual(addedRepos, want) {
+		t.Errorf("added repos = %v, want %v", addedRepos, want)
+	}
+
+	// 导出后与原文件相同，包括地址的协议
+	exported := ExportVcsRepos(config, DefaultSourceName(config))
+	if !reflect.DeepEqual(exported, vcsRepos) {
+		t.Errorf("exported repos = %+v, want %+v", exported.Repositories, vcsRepos.Repositories)
+	}
+
+	// 写入后再读取，导入到新的配置项中得到相同的结果
+	var buffer bytes.Buffer
+	if err := WriteVcsRepos(&buffer, exported); err != nil {
+		t.Fatal(err)
+	}
+	filePath := filepath.Join(t.TempDir(), "exported.repos")
+	if err := os.WriteFile(filePath, buffer.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reread, err := ReadVcsRepos(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reimported := &Config{Storage: config.Storage}
+	if _, err := ImportVcsRepos(reimported, reread); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reimported, config) {
+		t.Errorf("reimported config = %+v, want %+v", reimported, config)
+	}
+}
+
+// TestVcsReposHttpsScheme 导入 https 地址时记录协议，SSH 地址不记录
+func TestVcsReposHttpsScheme(t *testing.T) {
+	vcsRepos, err := ReadVcsRepos(filepath.Join("testdata", "vcstool", "https.repos"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := newVcsTestConfig(t)
+	if _, err := ImportVcsRepos(config, vcsRepos); err != nil {
+		t.Fatal(err)
+	}
+
+	for sourceName, wantScheme := range map[string]string{"github.com-team": "https", "gitea.example.com-ops": ""} {
+		source, ok := config.Source[sourceName]
+		if !ok {
+			t.Fatalf("source %s not found in %+v", sourceName, config.Source)
+		}
+		if source.Scheme != wantScheme {
+			t.Errorf("scheme of source %s = %q, want %q", sourceName, source.Scheme, wantScheme)
+		}
+	}
+
+	// 与存储库源协议不同的存储库单独记录协议
+	if scheme := config.Repo["parser"].Scheme; scheme != "https" {
+		t.Errorf("scheme of repo parser = %q, want https", scheme)
+	}
+	if scheme := config.Repo["tools"].Scheme; scheme != "" {
+		t.Errorf("scheme of repo tools = %q, want empty", scheme)
+	}
+
+	exported := ExportVcsRepos(config, DefaultSourceName(config))
+	if got := exported.Repositories["src/common"].Url; got != "https://github.com/team/common.git" {
+		t.Errorf("url of src/common = %s, want https url", got)
+	}
+}
+
+// TestVcsReposCollision 不同路径的同名存储库来自不同用户或主机时以不同的存储库键导入，导出后地址不变
+func TestVcsReposCollision(t *testing.T) {
+	vcsRepos, err := ReadVcsRepos(filepath.Join("testdata", "vcstool", "collision.repos"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := newVcsTestConfig(t)
+	addedRepos, err := ImportVcsRepos(config, vcsRepos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"common", "other/common", "team/common"}; !reflect.DeepEqual(addedRepos, want) {
+		t.Errorf("added repos = %v, want %v", addedRepos, want)
+	}
+	if repoConfig := config.Repo["common"]; repoConfig.Path != "src/x/common" || repoConfig.Source != "github.com-team" {
+		t.Errorf("repo config = %+v, want the first repository unchanged", repoConfig)
+	}
+	if repoConfig := config.Repo["other/common"]; repoConfig.Source != "github.com-other" {
+		t.Errorf("repo config = %+v, want source github.com-other", repoConfig)
+	}
+
+	// 再次导入不会重复添加
+	if addedRepos, err := ImportVcsRepos(config, vcsRepos); err != nil || len(addedRepos) != 0 {
+		t.Errorf("reimport added %v (err %v), want nothing", addedRepos, err)
+	}
+
+	exported := ExportVcsRepos(config, DefaultSourceName(config))
+	if !reflect.DeepEqual(exported, vcsRepos) {
+		t.Errorf("exported repos = %+v, want %+v", exported.Repositories, vcsRepos.Repositories)
+	}
+}